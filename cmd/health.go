@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"microblog/internal/database"
+	"microblog/internal/handler"
+)
+
+// readinessChecks — зависимости, без которых экземпляр не должен получать трафик; состояние
+// фоновых задач проверяет само приложение
+func readinessChecks(db *gorm.DB) []handler.HealthCheck {
+	return []handler.HealthCheck{
+		{
+			Name: "database",
+			Check: func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			// Код новее схемы, пока миграции не применены
+			Name: "migrations",
+			Check: func(ctx context.Context) error {
+				pending, err := database.PendingMigrations(db.WithContext(ctx))
+				if err != nil {
+					return err
+				}
+				if len(pending) > 0 {
+					return fmt.Errorf("%d pending migrations, next is %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+				}
+				return nil
+			},
+		},
+	}
+}
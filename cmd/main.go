@@ -1,34 +1,136 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"microblog/internal/config"
 	"microblog/internal/database"
-	"microblog/internal/router"
-	"microblog/internal/util"
+	"microblog/internal/lockout"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"os"
 )
 
+const usage = `Usage: microblog [command]
+
+Commands:
+  serve                  start the HTTP API (default)
+
+  migrate up             apply pending migrations
+  migrate down [n]       roll back the last n migrations (default 1)
+  migrate status         list migrations and whether they are applied
+  migrate create <name>  add an empty migration to internal/database/migrations
+
+  user create --username <name> --email <email> [--password <password>] [--role user|moderator|admin]
+  user promote --user <name> [--role admin|moderator|user]
+  user disable --user <name>
+  user enable --user <name>
+  user reset-password --user <name> [--password <password>]
+
+  post delete --id <id>
+
+  tokens revoke --user <name>   end all sessions and revoke access, personal and OAuth tokens
+
+  seed [--users n] [--posts n] [--password <password>]   fill an empty database with demo content
+
+Passwords that are not given are generated and printed once.
+`
+
 func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
 	// Загружаем конфигурацию с проверкой ошибок
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
 	}
 
-	// Инициализируем JWT
-	util.InitJWT(cfg)
+	switch command {
+	case "serve":
+		runServe(cfg)
+	case "migrate":
+		runMigrate(cfg, args)
+	case "user":
+		runUser(cfg, args)
+	case "post":
+		runPost(cfg, args)
+	case "tokens":
+		runTokens(cfg, args)
+	case "seed":
+		runSeed(cfg, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		exitWithUsage("unknown command %q", command)
+	}
+}
+
+func exitWithUsage(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n\n%s", append(args, usage)...)
+	os.Exit(2)
+}
+
+// parseFlags разбирает флаги подкоманды; required перечисляет флаги без значения по умолчанию
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) {
+	fs.Parse(args)
+
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			exitWithUsage("%s: --%s is required", fs.Name(), name)
+		}
+	}
+	if fs.NArg() > 0 {
+		exitWithUsage("%s: unexpected argument %q", fs.Name(), fs.Arg(0))
+	}
+}
+
+// openRepositories подключает команды администрирования к базе и хранилищам, общим с сервером
+func openRepositories(cfg *config.Config) *repository.Repositories {
+	return repository.New(database.InitDB(cfg))
+}
 
-	// Инициализируем базу данных
-	database.InitDB(cfg)
+// openRevoker открывает хранилище отозванных токенов, общее с сервером
+func openRevoker(cfg *config.Config, repos *repository.Repositories) *revocation.Revoker {
+	// Отзыв в памяти живёт только внутри процесса и до сервера не дойдёт
+	if cfg.JWT.RevocationStore == "memory" {
+		log.Print("Warning: JWT_REVOCATION_STORE=memory, issued access tokens stay valid until they expire")
+	}
+	revoker, err := revocation.New(cfg, repos.Revocations)
+	if err != nil {
+		log.Fatal("Failed to init token revocation:", err)
+	}
+	return revoker
+}
 
-	// Настраиваем роутер
-	r := router.Routers()
+func openLimiter(cfg *config.Config, repos *repository.Repositories) *lockout.Limiter {
+	limiter, err := lockout.New(cfg, repos.LoginAttempts)
+	if err != nil {
+		log.Fatal("Failed to init login lockout:", err)
+	}
+	return limiter
+}
 
-	// Используем порт из конфигурации
-	serverAddr := ":" + cfg.Server.Port
-	log.Printf("Server starting on port %s", cfg.Server.Port)
+func mustGetUser(repos *repository.Repositories, username string) *model.User {
+	user, err := repos.Users.GetUserByUsername(username)
+	if err != nil {
+		log.Fatalf("User %q not found: %v", username, err)
+	}
+	return user
+}
 
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatal("Ошибка запуска сервера:", err)
+// recordSecurityEvent отмечает в журнале пользователя действие, выполненное из командной строки
+func recordSecurityEvent(repos *repository.Repositories, userID int64, eventType, details string) {
+	err := repos.SecurityEvents.CreateSecurityEvent(&model.SecurityEvent{
+		UserID:  userID,
+		Type:    eventType,
+		Details: details,
+	})
+	if err != nil {
+		log.Printf("Failed to record security event %s for user %d: %v", eventType, userID, err)
 	}
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"microblog/internal/config"
+	"microblog/internal/database"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate управляет схемой базы: migrate up|down [n]|status|create <name>
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		exitWithUsage("migrate: command is required")
+	}
+
+	switch args[0] {
+	case "up":
+		applyMigrations(database.InitDB(cfg))
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of migrations to roll back: %q", args[1])
+			}
+			steps = n
+		}
+
+		rolledBack, err := database.MigrateDown(database.InitDB(cfg), steps)
+		for _, migration := range rolledBack {
+			log.Printf("Rolled back migration %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		if len(rolledBack) == 0 {
+			log.Print("No applied migrations to roll back")
+		}
+
+	case "status":
+		status, err := database.GetMigrationStatus(database.InitDB(cfg))
+		if err != nil {
+			log.Fatal("Failed to read migration status: ", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, entry := range status {
+			appliedAt := "pending"
+			if entry.AppliedAt != nil {
+				appliedAt = entry.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", entry.Version, entry.Name, appliedAt)
+		}
+		w.Flush()
+
+	case "create":
+		if len(args) < 2 {
+			log.Fatal("Usage: migrate create <name>")
+		}
+		paths, err := database.CreateMigration(database.MigrationsDir, args[1])
+		if err != nil {
+			log.Fatal("Failed to create migration: ", err)
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+
+	default:
+		exitWithUsage("unknown migrate command %q", args[0])
+	}
+}
+
+// applyMigrations применяет ожидающие миграции; параллельные запуски ждут друг друга на блокировке
+func applyMigrations(db *gorm.DB) {
+	applied, err := database.MigrateUp(db)
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		log.Fatal("Migration failed: ", err)
+	}
+	if len(applied) == 0 {
+		log.Print("Database schema is up to date")
+	}
+}
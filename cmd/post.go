@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"microblog/internal/config"
+	"strconv"
+)
+
+// runPost — модерация контента из командной строки
+func runPost(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		exitWithUsage("post: command is required")
+	}
+
+	switch args[0] {
+	case "delete":
+		deletePost(cfg, args[1:])
+	default:
+		exitWithUsage("unknown post command %q", args[0])
+	}
+}
+
+func deletePost(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("post delete", flag.ExitOnError)
+	rawID := fs.String("id", "", "post id")
+	parseFlags(fs, args, "id")
+
+	id, err := strconv.ParseInt(*rawID, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid post id %q", *rawID)
+	}
+
+	repos := openRepositories(cfg)
+	post, err := repos.Posts.GetPostByID(id)
+	if err != nil {
+		log.Fatalf("Post %d not found: %v", id, err)
+	}
+
+	// Комментарии удаляются вместе с постом
+	if err := repos.Posts.DeletePost(post.ID); err != nil {
+		log.Fatal("Failed to delete post: ", err)
+	}
+	fmt.Printf("Deleted post %d %q by %s with %d comments\n", post.ID, post.Title, post.Author.Username, post.CommentsCount)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"microblog/internal/config"
+	"microblog/internal/model"
+)
+
+// runSeed наполняет базу демонстрационными пользователями, постами и комментариями для локальной разработки.
+// Уже существующих пользователей demoN команда не трогает, поэтому её можно запускать повторно.
+func runSeed(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	usersCount := fs.Int("users", 3, "number of demo users")
+	postsCount := fs.Int("posts", 5, "posts per demo user")
+	password := fs.String("password", "password", "password of demo users")
+	parseFlags(fs, args)
+
+	if *usersCount < 1 || *postsCount < 0 {
+		exitWithUsage("seed: --users must be positive and --posts must not be negative")
+	}
+
+	repos := openRepositories(cfg)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatal("Failed to hash password: ", err)
+	}
+
+	var created []*model.User
+	for i := 1; i <= *usersCount; i++ {
+		username := fmt.Sprintf("demo%d", i)
+		if user, _ := repos.Users.GetUserByUsername(username); user != nil {
+			fmt.Printf("Skipped existing user %s\n", username)
+			continue
+		}
+
+		user, err := repos.Users.CreateUser(&model.User{
+			Username:      username,
+			Email:         username + "@example.com",
+			Password:      string(hashedPassword),
+			Role:          model.RoleUser,
+			EmailVerified: true,
+			DisplayName:   fmt.Sprintf("Demo User %d", i),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create user %s: %v", username, err)
+		}
+		created = append(created, user)
+	}
+
+	// Каждый пост получает комментарий от остальных новых пользователей
+	var posts, comments int
+	for _, author := range created {
+		for i := 1; i <= *postsCount; i++ {
+			post, err := repos.Posts.CreatePost(&model.Post{
+				Title:    fmt.Sprintf("Post %d by %s", i, author.Username),
+				Content:  fmt.Sprintf("This is demo post number %d written by %s.", i, author.Username),
+				AuthorID: author.ID,
+			})
+			if err != nil {
+				log.Fatalf("Failed to create post: %v", err)
+			}
+			posts++
+
+			for _, commenter := range created {
+				if commenter.ID == author.ID {
+					continue
+				}
+				_, err := repos.Comments.CreateComment(&model.Comment{
+					Content:  fmt.Sprintf("%s commenting on %q", commenter.Username, post.Title),
+					PostID:   post.ID,
+					AuthorID: commenter.ID,
+				})
+				if err != nil {
+					log.Fatalf("Failed to create comment: %v", err)
+				}
+				comments++
+			}
+		}
+	}
+
+	fmt.Printf("Created %d users, %d posts and %d comments\n", len(created), posts, comments)
+	if len(created) > 0 {
+		fmt.Printf("Demo users sign in as demoN@example.com with password %q\n", *password)
+	}
+}
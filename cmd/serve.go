@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"microblog/internal/app"
+	"microblog/internal/config"
+	"microblog/internal/database"
+	"microblog/internal/mailer"
+	"microblog/internal/repository"
+	"microblog/internal/util"
+	"microblog/internal/worker"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runServe запускает HTTP API и фоновые задачи
+func runServe(cfg *config.Config) {
+	// Инициализируем JWT
+	if err := util.InitJWT(cfg); err != nil {
+		log.Fatal("Failed to init JWT:", err)
+	}
+
+	// Инициализируем базу данных и хранилища поверх неё
+	db := database.InitDB(cfg)
+	if cfg.Database.AutoMigrate {
+		applyMigrations(db)
+	}
+	repos := repository.New(db)
+
+	// Отправка писем
+	mail, err := mailer.NewTransport(cfg)
+	if err != nil {
+		log.Fatal("Failed to init mailer:", err)
+	}
+
+	// Обработчики, фоновые задачи и всё, что им нужно
+	a, err := app.New(cfg, repos, mail, readinessChecks(db)...)
+	if err != nil {
+		log.Fatal("Failed to init application:", err)
+	}
+	a.Start()
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           a.Engine,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Server.Port)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		log.Print("Ошибка запуска сервера: ", err)
+		exitCode = 1
+	case <-ctx.Done():
+		// Повторный сигнал завершает процесс сразу
+		stop()
+
+		// Пока балансировщик не заметил неготовность, запросы ещё приходят и обслуживаются
+		a.Handlers.Health.Drain()
+		log.Printf("Shutting down in %s, readiness is failing", cfg.Server.ShutdownDelay)
+		time.Sleep(cfg.Server.ShutdownDelay)
+		log.Print("Waiting for in-flight requests")
+	}
+
+	if !shutdown(srv, a.Worker, db, cfg.Server.ShutdownTimeout) {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+// shutdown перестаёт принимать запросы, дожидается текущих и фоновых задач и закрывает пул соединений.
+// Все шаги делят один таймаут; возвращает false, если что-то не успело завершиться.
+func shutdown(srv *http.Server, workers *worker.Pool, db *gorm.DB, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ok := true
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server did not drain in time: %v", err)
+		srv.Close()
+		ok = false
+	}
+
+	if err := workers.Stop(ctx); err != nil {
+		log.Printf("Background tasks did not stop in time: %v", err)
+		ok = false
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("Failed to close database connections: %v", err)
+			ok = false
+		}
+	}
+
+	log.Print("Server stopped")
+	return ok
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"microblog/internal/config"
+	"microblog/internal/model"
+)
+
+// runTokens — отзыв учётных данных, например после утечки
+func runTokens(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		exitWithUsage("tokens: command is required")
+	}
+
+	switch args[0] {
+	case "revoke":
+		revokeTokens(cfg, args[1:])
+	default:
+		exitWithUsage("unknown tokens command %q", args[0])
+	}
+}
+
+// revokeTokens завершает все сессии пользователя и отзывает все его токены; пароль не меняется
+func revokeTokens(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("tokens revoke", flag.ExitOnError)
+	username := fs.String("user", "", "username")
+	parseFlags(fs, args, "user")
+
+	repos := openRepositories(cfg)
+	revoker := openRevoker(cfg, repos)
+	user := mustGetUser(repos, *username)
+
+	if err := repos.Sessions.DeleteUserSessions(user.ID); err != nil {
+		log.Fatal("Failed to end sessions: ", err)
+	}
+	if err := revoker.RevokeUserTokens(user.Username); err != nil {
+		log.Fatal("Failed to revoke access tokens: ", err)
+	}
+	if err := repos.PersonalAccessTokens.DeleteUserPersonalAccessTokens(user.ID); err != nil {
+		log.Fatal("Failed to delete personal access tokens: ", err)
+	}
+	if err := repos.OAuth.DeleteUserOAuthRefreshTokens(user.ID); err != nil {
+		log.Fatal("Failed to revoke OAuth grants: ", err)
+	}
+
+	recordSecurityEvent(repos, user.ID, model.SecurityEventTokensRevoked, cliActor)
+	fmt.Printf("Revoked all sessions and tokens of %s\n", user.Username)
+}
@@ -0,0 +1,125 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"microblog/internal/config"
+	"microblog/internal/lockout"
+	"microblog/internal/mailer"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"os"
+	"time"
+)
+
+// Способы обращения с контентом удалённого пользователя
+const (
+	DeletionModeAnonymize = "anonymize"
+	DeletionModeDelete    = "delete"
+)
+
+// Сколько аккаунтов удаляется за один проход задачи
+const deletionBatchSize = 50
+
+// Jobs — фоновые задачи жизненного цикла аккаунтов: окончательное удаление и выгрузка данных
+type Jobs struct {
+	repos   *repository.Repositories
+	revoker *revocation.Revoker
+	limiter *lockout.Limiter
+	mail    *mailer.Sender
+
+	gracePeriod  time.Duration
+	deletionMode string
+	exportDir    string
+	exportTTL    time.Duration
+}
+
+func New(cfg *config.Config, repos *repository.Repositories, revoker *revocation.Revoker,
+	limiter *lockout.Limiter, mail *mailer.Sender) (*Jobs, error) {
+	switch cfg.Account.DeletionMode {
+	case DeletionModeAnonymize, DeletionModeDelete:
+	default:
+		return nil, fmt.Errorf("unknown account deletion mode %q", cfg.Account.DeletionMode)
+	}
+	if cfg.Account.DeletionGracePeriod < 0 {
+		return nil, errors.New("account deletion grace period must not be negative")
+	}
+	if cfg.Account.ExportTTL <= 0 {
+		return nil, errors.New("data export TTL must be positive")
+	}
+	if err := os.MkdirAll(cfg.Account.ExportDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	return &Jobs{
+		repos:        repos,
+		revoker:      revoker,
+		limiter:      limiter,
+		mail:         mail,
+		gracePeriod:  cfg.Account.DeletionGracePeriod,
+		deletionMode: cfg.Account.DeletionMode,
+		exportDir:    cfg.Account.ExportDir,
+		exportTTL:    cfg.Account.ExportTTL,
+	}, nil
+}
+
+func (j *Jobs) DeletionGracePeriod() time.Duration {
+	return j.gracePeriod
+}
+
+// PurgeDueAccounts окончательно удаляет аккаунты, у которых истёк срок отмены удаления.
+// Ошибка с одним аккаунтом не останавливает остальных; все ошибки возвращаются вместе.
+func (j *Jobs) PurgeDueAccounts(ctx context.Context) error {
+	var errs []error
+	for {
+		now := time.Now()
+		// Аккаунты, которые не удалось удалить, остаются в начале выборки, их пропускаем
+		users, err := j.repos.Users.GetUsersDueForDeletion(now, deletionBatchSize, len(errs))
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return errors.Join(append(errs, ctx.Err())...)
+			}
+
+			if err := j.purgeAccount(&user, now); err != nil {
+				log.Printf("Failed to erase user %d: %v", user.ID, err)
+				errs = append(errs, fmt.Errorf("failed to erase user %d: %w", user.ID, err))
+			}
+		}
+
+		if len(users) < deletionBatchSize {
+			return errors.Join(errs...)
+		}
+	}
+}
+
+func (j *Jobs) purgeAccount(user *model.User, now time.Time) error {
+	// Граница отзыва в строке users пропадёт вместе с ней, поэтому сохраняем её отдельно
+	if err := j.revoker.RevokeErasedUserTokens(user.Username); err != nil {
+		return err
+	}
+
+	erased, err := j.repos.Users.EraseUser(user.ID, j.deletionMode == DeletionModeAnonymize, now)
+	if errors.Is(err, repository.ErrDeletionNotDue) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Транзакция зафиксирована: теперь можно убрать то, что хранится вне неё
+	if err := j.limiter.Unlock(erased.Email); err != nil {
+		log.Printf("Failed to clear login attempts of erased user %d: %v", erased.ID, err)
+	}
+	if err := os.RemoveAll(j.userExportDir(erased.ID)); err != nil {
+		log.Printf("Failed to remove data exports of erased user %d: %v", erased.ID, err)
+	}
+	log.Printf("Account %d erased (%s)", erased.ID, j.deletionMode)
+	return nil
+}
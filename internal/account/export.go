@@ -0,0 +1,409 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"microblog/internal/mailer"
+	"microblog/internal/model"
+	"microblog/internal/util"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Сколько выгрузок собирается за один проход задачи
+	exportBatchSize = 5
+	// Выгрузка в статусе processing дольше этого срока считается брошенной и собирается заново
+	exportStaleAfter = time.Hour
+	exportPageSize   = 500
+	// Ссылка на скачивание живёт не дольше часа, но и не дольше самого архива
+	exportLinkTTL = time.Hour
+)
+
+type exportProfile struct {
+	ID                  int64      `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	Role                string     `json:"role"`
+	TOTPEnabled         bool       `json:"totp_enabled"`
+	DisplayName         string     `json:"display_name"`
+	Bio                 string     `json:"bio"`
+	AvatarURL           string     `json:"avatar_url"`
+	Website             string     `json:"website"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+type exportPost struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type exportComment struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExportTTL — сколько готовый архив хранится до удаления
+func (j *Jobs) ExportTTL() time.Duration {
+	return j.exportTTL
+}
+
+// ExportDownloadLink выдаёт ссылку на скачивание готового архива
+func (j *Jobs) ExportDownloadLink(export *model.DataExport, username string) (string, error) {
+	if export.Status != model.DataExportReady || export.ExpiresAt == nil {
+		return "", errors.New("export is not ready")
+	}
+
+	ttl := time.Until(*export.ExpiresAt)
+	if ttl <= 0 {
+		return "", errors.New("export has expired")
+	}
+	if ttl > exportLinkTTL {
+		ttl = exportLinkTTL
+	}
+
+	token, err := util.GenerateActionToken(util.PurposeDataExport, username, "", strconv.FormatInt(export.ID, 10), ttl)
+	if err != nil {
+		return "", err
+	}
+	return j.mail.Link("/api/exports/download", url.Values{"token": {token}}), nil
+}
+
+// ProcessDataExports собирает ожидающие архивы и удаляет просроченные
+func (j *Jobs) ProcessDataExports(ctx context.Context) error {
+	if err := j.removeExpiredExports(time.Now()); err != nil {
+		return err
+	}
+
+	for {
+		now := time.Now()
+		exports, err := j.repos.DataExports.GetQueuedDataExports(now.Add(-exportStaleAfter), exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range exports {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			export := &exports[i]
+			claimed, err := j.repos.DataExports.ClaimDataExport(export, time.Now())
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+
+			if err := j.runDataExport(ctx, export); err != nil {
+				log.Printf("Data export %d of user %d failed: %v", export.ID, export.UserID, err)
+				if err := j.repos.DataExports.FailDataExport(export.ID, time.Now()); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(exports) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func (j *Jobs) runDataExport(ctx context.Context, export *model.DataExport) error {
+	path := j.exportFilePath(export.UserID, export.ID)
+	size, err := j.writeExportArchive(ctx, path, &export.User)
+	if err != nil {
+		return err
+	}
+
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(j.exportTTL)
+	if err := j.repos.DataExports.CompleteDataExport(export.ID, path, size, completedAt, expiresAt); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	export.Status = model.DataExportReady
+	export.ExpiresAt = &expiresAt
+	link, err := j.ExportDownloadLink(export, export.User.Username)
+	if err != nil {
+		// Архив готов, ссылку пользователь получит через статус выгрузки
+		log.Printf("Failed to create download link for data export %d: %v", export.ID, err)
+		return nil
+	}
+
+	err = j.mail.Send(mailer.Message{
+		To:      export.User.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf("Hi %s,\n\nThe archive with your microblog data is ready. Download it here:\n\n%s\n\n"+
+			"The link is valid for one hour; after that you can get a new one in your account settings. "+
+			"The archive itself will be deleted on %s.\n",
+			export.User.Username, link, expiresAt.UTC().Format("2 January 2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %d about data export: %v", export.UserID, err)
+	}
+	return nil
+}
+
+func (j *Jobs) removeExpiredExports(now time.Time) error {
+	exports, err := j.repos.DataExports.GetExpiredDataExports(now)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Failed to remove data export %d: %v", export.ID, err)
+				continue
+			}
+		}
+		if err := j.repos.DataExports.DeleteDataExport(export.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *Jobs) userExportDir(userID int64) string {
+	return filepath.Join(j.exportDir, strconv.FormatInt(userID, 10))
+}
+
+func (j *Jobs) exportFilePath(userID, exportID int64) string {
+	return filepath.Join(j.userExportDir(userID), strconv.FormatInt(exportID, 10)+".zip")
+}
+
+// writeExportArchive пишет архив во временный файл и переименовывает его,
+// чтобы по ссылке нельзя было получить недописанный файл
+func (j *Jobs) writeExportArchive(ctx context.Context, path string, user *model.User) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := zip.NewWriter(tmp)
+	if err := j.writeExportFiles(ctx, zw, user); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (j *Jobs) writeExportFiles(ctx context.Context, zw *zip.Writer, user *model.User) error {
+	generatedAt := time.Now().UTC()
+
+	profile := exportProfile{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		Role:                user.Role,
+		TOTPEnabled:         user.TOTPEnabled,
+		DisplayName:         user.DisplayName,
+		Bio:                 user.Bio,
+		AvatarURL:           user.AvatarURL,
+		Website:             user.Website,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+
+	var posts []exportPost
+	for offset := 0; ; offset += exportPageSize {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		page, err := j.repos.Posts.GetPostsByAuthor(user.ID, exportPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to load posts: %w", err)
+		}
+		for _, post := range page {
+			posts = append(posts, exportPost{
+				ID:        post.ID,
+				Title:     post.Title,
+				Content:   post.Content,
+				CreatedAt: post.CreatedAt,
+				UpdatedAt: post.UpdatedAt,
+			})
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	var comments []exportComment
+	for offset := 0; ; offset += exportPageSize {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		page, err := j.repos.Comments.GetCommentsByAuthor(user.ID, exportPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to load comments: %w", err)
+		}
+		for _, comment := range page {
+			comments = append(comments, exportComment{
+				ID:        comment.ID,
+				PostID:    comment.PostID,
+				Content:   comment.Content,
+				CreatedAt: comment.CreatedAt,
+				UpdatedAt: comment.UpdatedAt,
+			})
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	sessions, err := j.repos.Sessions.GetActiveSessionsByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+	events, err := j.repos.SecurityEvents.GetSecurityEventsByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load login history: %w", err)
+	}
+	tokens, err := j.repos.PersonalAccessTokens.GetPersonalAccessTokensByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load access tokens: %w", err)
+	}
+	identities, err := j.repos.UserIdentities.GetUserIdentitiesByUserID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load linked accounts: %w", err)
+	}
+	clients, err := j.repos.OAuth.GetOAuthClientsByOwnerID(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load applications: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"posts.json", emptyIfNil(posts)},
+		{"comments.json", emptyIfNil(comments)},
+		{"sessions.json", sessions},
+		{"login_history.json", events},
+		{"access_tokens.json", tokens},
+		{"linked_accounts.json", identities},
+		{"applications.json", clients},
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeZipFile(zw, file.name, data, generatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := writeZipFile(zw, "posts.md", []byte(postsMarkdown(posts)), generatedAt); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "comments.md", []byte(commentsMarkdown(comments)), generatedAt); err != nil {
+		return err
+	}
+	return writeZipFile(zw, "README.md", []byte(exportReadme(user, generatedAt)), generatedAt)
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte, modified time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// emptyIfNil нужен, чтобы в JSON был пустой массив, а не null
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func exportReadme(user *model.User, generatedAt time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Data export for %s\n\n", user.Username)
+	fmt.Fprintf(&b, "Generated on %s.\n\n", generatedAt.Format("2 January 2006 15:04 MST"))
+	b.WriteString("| File | Contents |\n|------|----------|\n")
+	b.WriteString("| profile.json | Account and profile details |\n")
+	b.WriteString("| posts.json, posts.md | All your posts |\n")
+	b.WriteString("| comments.json, comments.md | All your comments |\n")
+	b.WriteString("| sessions.json | Devices currently signed in |\n")
+	b.WriteString("| login_history.json | Sign-ins and other security events |\n")
+	b.WriteString("| access_tokens.json | Personal access tokens (without the secrets) |\n")
+	b.WriteString("| linked_accounts.json | External sign-in providers linked to the account |\n")
+	b.WriteString("| applications.json | OAuth applications you registered |\n")
+	return b.String()
+}
+
+func postsMarkdown(posts []exportPost) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Posts (%d)\n", len(posts))
+	for _, post := range posts {
+		fmt.Fprintf(&b, "\n## %s\n\n", post.Title)
+		fmt.Fprintf(&b, "_Post #%d, published %s_\n\n", post.ID, post.CreatedAt.UTC().Format("2006-01-02 15:04 MST"))
+		b.WriteString(post.Content)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func commentsMarkdown(comments []exportComment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Comments (%d)\n", len(comments))
+	for _, comment := range comments {
+		fmt.Fprintf(&b, "\n## On post #%d, %s\n\n", comment.PostID, comment.CreatedAt.UTC().Format("2006-01-02 15:04 MST"))
+		b.WriteString(comment.Content)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
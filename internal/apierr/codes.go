@@ -0,0 +1,73 @@
+package apierr
+
+// Коды ошибок входят в контракт API: менять или переиспользовать их нельзя.
+// Тексты сообщений для каждого кода лежат в каталогах пакета i18n.
+const (
+	// Общие
+	CodeBadRequest       = "bad_request"
+	CodeMalformedBody    = "malformed_body"
+	CodeEmptyBody        = "empty_body"
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidID        = "invalid_id"
+	CodeNotFound         = "not_found"
+	CodeNotOwner         = "not_owner"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+
+	// Аутентификация и доступ
+	CodeUnauthorized       = "unauthorized"
+	CodeTokenMissing       = "token_missing"
+	CodeTokenInvalid       = "token_invalid"
+	CodeTokenRevoked       = "token_revoked"
+	CodeInsufficientScope  = "insufficient_scope"
+	CodeInsufficientRole   = "insufficient_role"
+	CodeSessionRequired    = "session_required"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeAccountLocked      = "account_locked"
+	CodeAccountDisabled    = "account_disabled"
+	CodeTooManyAttempts    = "too_many_attempts"
+	CodeRefreshTokenReuse  = "refresh_token_reuse"
+	// Ссылка или токен из письма, MFA-токен, refresh-токен недействительны или истекли
+	CodeInvalidOrExpiredToken = "invalid_or_expired_token"
+
+	// Пользователи и аккаунт
+	CodeUserNotFound          = "user_not_found"
+	CodeUsernameTaken         = "username_taken"
+	CodeEmailTaken            = "email_taken"
+	CodeEmailUnchanged        = "email_unchanged"
+	CodeEmailNotVerified      = "email_not_verified"
+	CodeEmailAlreadyVerified  = "email_already_verified"
+	CodeIncorrectPassword     = "incorrect_password"
+	CodeOwnRoleChange         = "own_role_change"
+	CodeDeletionScheduled     = "deletion_already_scheduled"
+	CodeDeletionNotScheduled  = "deletion_not_scheduled"
+	CodeExportInProgress      = "export_in_progress"
+	CodeExportNotFound        = "export_not_found"
+	CodeSessionNotFound       = "session_not_found"
+	CodeAccessTokenNotFound   = "access_token_not_found"
+	CodeOAuthClientNotFound   = "oauth_client_not_found"
+	CodeInvalidMFACode        = "invalid_mfa_code"
+	CodeMFAAlreadyEnabled     = "mfa_already_enabled"
+	CodeMFANotEnabled         = "mfa_not_enabled"
+	CodeMFASetupNotStarted    = "mfa_setup_not_started"
+	CodeVerificationEmailSent = "verification_email_recently_sent"
+
+	// Контент
+	CodePostNotFound    = "post_not_found"
+	CodeCommentNotFound = "comment_not_found"
+
+	// Вход через внешних провайдеров
+	CodeUnknownProvider     = "unknown_provider"
+	CodeLoginStateInvalid   = "login_state_invalid"
+	CodeProviderDenied      = "provider_denied"
+	CodeProviderError       = "provider_error"
+	CodeProviderNoEmail     = "provider_no_email"
+	CodeProviderUnavailable = "provider_unavailable"
+	CodeAccountConflict     = "account_conflict"
+
+	// Запрос авторизации стороннего приложения
+	CodeRedirectURINotRegistered = "redirect_uri_not_registered"
+	CodeUnsupportedResponseType  = "unsupported_response_type"
+	CodePKCERequired             = "pkce_required"
+	CodeScopeNotAllowed          = "scope_not_allowed"
+)
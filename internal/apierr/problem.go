@@ -0,0 +1,124 @@
+package apierr
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/i18n"
+	"net/http"
+)
+
+// ContentType — тип ответа с ошибкой из RFC 7807
+const ContentType = "application/problem+json"
+
+// Problem — тело ответа с ошибкой (RFC 7807). Code — стабильный машиночитаемый код,
+// на который могут опираться клиенты; Detail — сообщение для человека на языке запроса,
+// берётся из каталога i18n по коду.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	Code     string
+	Errors   []FieldError
+	// Дополнительные поля, например deletion_scheduled_at
+	Extensions map[string]interface{}
+
+	args []interface{}
+}
+
+// FieldError описывает ошибку в одном поле запроса; Field — имя поля в JSON
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	key   string
+	param string
+}
+
+// Invalid — ошибка поля для проверок, которые не выражаются тегами binding;
+// сообщение берётся из каталога по ключу validation.<rule>
+func Invalid(field, rule string) FieldError {
+	return FieldError{Field: field, Code: rule, key: "validation." + rule}
+}
+
+// New создаёт ошибку; args подставляются в сообщение из каталога
+func New(status int, code string, args ...interface{}) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		args:   args,
+	}
+}
+
+// With добавляет поле расширения к ответу
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) WithFields(fields ...FieldError) *Problem {
+	p.Errors = append(p.Errors, fields...)
+	return p
+}
+
+// Localize заполняет сообщения на выбранном языке
+func (p *Problem) Localize(locale string) {
+	p.Detail = i18n.T(locale, p.Code, p.args...)
+	for i := range p.Errors {
+		field := &p.Errors[i]
+		if field.key != "" {
+			field.Message = i18n.T(locale, field.key, field.Field, field.param)
+		}
+	}
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.Extensions)+7)
+	for key, value := range p.Extensions {
+		body[key] = value
+	}
+	body["type"] = p.Type
+	body["title"] = p.Title
+	body["status"] = p.Status
+	body["code"] = p.Code
+	if p.Detail != "" {
+		body["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		body["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		body["errors"] = p.Errors
+	}
+	return json.Marshal(body)
+}
+
+// Write отправляет ошибку клиенту на языке запроса
+func Write(c *gin.Context, p *Problem) {
+	locale := i18n.FromContext(c)
+	p.Localize(locale)
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", locale)
+	c.JSON(p.Status, p)
+}
+
+// Respond — короткая форма для ошибок без дополнительных полей
+func Respond(c *gin.Context, status int, code string, args ...interface{}) {
+	Write(c, New(status, code, args...))
+}
+
+// Abort отправляет ошибку и прерывает цепочку обработчиков; для middleware
+func Abort(c *gin.Context, status int, code string, args ...interface{}) {
+	Write(c, New(status, code, args...))
+	c.Abort()
+}
@@ -0,0 +1,130 @@
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	// В ошибках валидации поля называются так же, как в JSON запроса, а не как в Go
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Validation отвечает на ошибку ShouldBind*: для каждого поля указывается, что с ним не так
+func Validation(c *gin.Context, err error) {
+	Write(c, FromBindError(err))
+}
+
+// Fields отвечает ошибкой валидации для проверок, которые не выражаются тегами binding
+func Fields(c *gin.Context, fields ...FieldError) {
+	Write(c, New(http.StatusBadRequest, CodeValidationFailed).WithFields(fields...))
+}
+
+func FromBindError(err error) *Problem {
+	var validationErrors validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, newFieldError(fe))
+		}
+		return New(http.StatusBadRequest, CodeValidationFailed).WithFields(fields...)
+	case errors.As(err, &typeErr):
+		return New(http.StatusBadRequest, CodeValidationFailed).WithFields(FieldError{
+			Field: typeErr.Field,
+			Code:  "type",
+			key:   "validation.type",
+			param: jsonType(typeErr.Type),
+		})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return New(http.StatusBadRequest, CodeMalformedBody)
+	case errors.Is(err, io.EOF):
+		return New(http.StatusBadRequest, CodeEmptyBody)
+	}
+	return New(http.StatusBadRequest, CodeBadRequest)
+}
+
+func newFieldError(fe validator.FieldError) FieldError {
+	param := fe.Param()
+	if fe.Tag() == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	return FieldError{
+		Field: jsonPath(fe.Namespace()),
+		Code:  fe.Tag(),
+		key:   messageKey(fe),
+		param: param,
+	}
+}
+
+// jsonPath убирает из пути валидатора имя структуры запроса и встроенных структур:
+// у них нет имени в JSON, а поля запросов всегда называются в нижнем регистре
+func jsonPath(namespace string) string {
+	parts := strings.Split(namespace, ".")
+	path := parts[:0]
+	for _, part := range parts {
+		if part != "" && !unicode.IsUpper([]rune(part)[0]) {
+			path = append(path, part)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// messageKey выбирает сообщение каталога: у min и max оно зависит от типа поля
+func messageKey(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "email", "oneof":
+		return "validation." + fe.Tag()
+	case "min", "max":
+		switch fe.Kind() {
+		case reflect.String:
+			return "validation." + fe.Tag() + ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return "validation." + fe.Tag() + ".items"
+		}
+		return "validation." + fe.Tag() + ".number"
+	}
+	return "validation.invalid"
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
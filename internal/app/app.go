@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/account"
+	"microblog/internal/config"
+	"microblog/internal/handler"
+	"microblog/internal/lockout"
+	"microblog/internal/mailer"
+	"microblog/internal/oidc"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"microblog/internal/router"
+	"microblog/internal/service"
+	"microblog/internal/worker"
+	"time"
+)
+
+// App — собранное приложение: HTTP-обработчики и фоновые задачи поверх общих хранилищ
+type App struct {
+	Engine    *gin.Engine
+	Handlers  *handler.Handlers
+	Lifecycle *account.Jobs
+	Worker    *worker.Pool
+
+	oauth repository.OAuthRepository
+}
+
+// New собирает приложение; письма уходят через mail, а checks вместе с состоянием фоновых задач
+// определяют готовность принимать трафик. Фоновые задачи запускает Start.
+func New(cfg *config.Config, repos *repository.Repositories, mail mailer.Mailer, checks ...handler.HealthCheck) (*App, error) {
+	// Хранилище отозванных access-токенов
+	revoker, err := revocation.New(cfg, repos.Revocations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init token revocation: %w", err)
+	}
+
+	// Защита от подбора паролей
+	limiter, err := lockout.New(cfg, repos.LoginAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init login lockout: %w", err)
+	}
+
+	sender := mailer.NewSender(mail, cfg.Server.PublicURL)
+
+	// Внешние провайдеры входа
+	providers, err := oidc.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init OIDC providers: %w", err)
+	}
+
+	// Удаление аккаунтов по истечении срока отмены и выгрузка данных
+	jobs, err := account.New(cfg, repos, revoker, limiter, sender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init account lifecycle: %w", err)
+	}
+
+	pool := worker.New()
+	checks = append(checks, handler.HealthCheck{
+		Name: "workers",
+		Check: func(context.Context) error {
+			if !pool.Running() {
+				return errors.New("background workers are not running")
+			}
+			return nil
+		},
+	})
+
+	// Бизнес-правила постов, комментариев, сессий и аккаунтов
+	posts := service.NewPostService(repos.Posts)
+	comments := service.NewCommentService(repos.Comments, posts)
+	users := service.NewUserService(repos.Users, repos.Posts)
+	sessions := service.NewSessionService(repos.Sessions, revoker)
+	accounts := service.NewAccountService(repos.Users, repos.PasswordResets, sessions, revoker, limiter, jobs.DeletionGracePeriod())
+
+	handlers := &handler.Handlers{
+		Posts:    handler.NewPostHandler(posts, users),
+		Comments: handler.NewCommentHandler(comments, posts, users),
+		Users:    handler.NewUserHandler(users, posts),
+		Auth: handler.NewAuthHandler(handler.AuthDependencies{
+			Users:          repos.Users,
+			SecurityEvents: repos.SecurityEvents,
+			TOTP:           repos.TOTP,
+			Tokens:         repos.PersonalAccessTokens,
+			Identities:     repos.UserIdentities,
+			DataExports:    repos.DataExports,
+			Sessions:       sessions,
+			Accounts:       accounts,
+			Revoker:        revoker,
+			Limiter:        limiter,
+			Mail:           sender,
+			Providers:      providers,
+			Lifecycle:      jobs,
+			Worker:         pool,
+		}),
+		OAuth:  handler.NewOAuthHandler(repos.Users, repos.OAuth, revoker),
+		Health: handler.NewHealthHandler(checks...),
+	}
+
+	engine, err := router.Routers(handlers, repos.PersonalAccessTokens, revoker, cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up router: %w", err)
+	}
+
+	return &App{
+		Engine:    engine,
+		Handlers:  handlers,
+		Lifecycle: jobs,
+		Worker:    pool,
+		oauth:     repos.OAuth,
+	}, nil
+}
+
+// Start запускает периодические задачи: удаление аккаунтов, выгрузку данных и очистку
+// записей об истёкших токенах приложений
+func (a *App) Start() {
+	a.Worker.Start(worker.Task{
+		Name:     "account-deletion",
+		Interval: time.Hour,
+		Run:      a.Lifecycle.PurgeDueAccounts,
+	}, worker.Task{
+		Name:     "data-export",
+		Interval: time.Minute,
+		Run:      a.Lifecycle.ProcessDataExports,
+	}, worker.Task{
+		Name:     "oauth-access-tokens",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			return a.oauth.DeleteExpiredOAuthAccessTokens()
+		},
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +15,10 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Server   ServerConfig
+	Mail     MailConfig
+	Lockout  LockoutConfig
+	OIDC     OIDCConfig
+	Account  AccountConfig
 }
 
 type DatabaseConfig struct {
@@ -22,15 +28,83 @@ type DatabaseConfig struct {
 	Name     string
 	Port     int
 	SSLMode  string
+	// Применять миграции при запуске сервера; иначе их запускают командой migrate up
+	AutoMigrate bool
 }
 
 type JWTConfig struct {
-	Secret        string
-	RefreshSecret string
+	Secret          string
+	RefreshSecret   string
+	RevocationStore string
+	// Секрет для одноразовых токенов из писем; по умолчанию используется RefreshSecret
+	ActionSecret string
+	// HS256, RS256 или EdDSA; для асимметричных алгоритмов ключи берутся из KeysDir
+	Algorithm           string
+	KeysDir             string
+	KeyRotationInterval time.Duration
+	KeyRetention        time.Duration
 }
 
 type ServerConfig struct {
 	Port string
+	// Адрес, на который ведут ссылки из писем
+	PublicURL string
+	// Ограничения http.Server: медленные клиенты не должны держать соединения бесконечно
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// Сколько после SIGTERM отвечать на /readyz ошибкой до остановки, чтобы балансировщик убрал экземпляр
+	ShutdownDelay time.Duration
+	// Сколько затем ждать завершения текущих запросов и фоновых задач
+	ShutdownTimeout time.Duration
+	// Адреса и подсети прокси, которым можно верить в X-Forwarded-For; по умолчанию никому,
+	// иначе клиент подставит в заголовок любой IP и обойдёт ограничение попыток входа по IP
+	TrustedProxies []string
+}
+
+type LockoutConfig struct {
+	// postgres или memory (только для одного экземпляра)
+	Store           string
+	MaxFailures     int
+	IPMaxFailures   int
+	LockoutDuration time.Duration
+}
+
+type AccountConfig struct {
+	// Сколько ждать перед удалением аккаунта; в это время удаление можно отменить
+	DeletionGracePeriod time.Duration
+	// anonymize — посты и комментарии переходят служебному пользователю deleted, delete — удаляются
+	DeletionMode string
+	// Каталог для архивов с данными пользователей и срок их хранения
+	ExportDir string
+	ExportTTL time.Duration
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig — внешний провайдер входа; Name используется в адресах /api/auth/oidc/:provider
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type MailConfig struct {
+	// smtp, file или log
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	FileDir      string
 }
 
 func LoadConfig() (*Config, error) {
@@ -40,24 +114,83 @@ func LoadConfig() (*Config, error) {
 
 	cfg := &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", "microblog"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:        getEnv("DB_HOST", "localhost"),
+			Port:        getEnvAsInt("DB_PORT", 5432),
+			User:        getEnv("DB_USER", "postgres"),
+			Password:    getEnv("DB_PASSWORD", ""),
+			Name:        getEnv("DB_NAME", "microblog"),
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+			AutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", true),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", ""),
-			RefreshSecret: getEnv("JWT_REFRESH_SECRET", ""),
+			Secret:              getEnv("JWT_SECRET", ""),
+			RefreshSecret:       getEnv("JWT_REFRESH_SECRET", ""),
+			RevocationStore:     getEnv("JWT_REVOCATION_STORE", "postgres"),
+			ActionSecret:        getEnv("JWT_ACTION_SECRET", ""),
+			Algorithm:           getEnv("JWT_ALGORITHM", "HS256"),
+			KeysDir:             getEnv("JWT_KEYS_DIR", ""),
+			KeyRotationInterval: getEnvAsDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+			KeyRetention:        getEnvAsDuration("JWT_KEY_RETENTION", 24*time.Hour),
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:              getEnv("PORT", "8080"),
+			PublicURL:         getEnv("PUBLIC_URL", "http://localhost:8080"),
+			ReadTimeout:       getEnvAsDuration("HTTP_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: getEnvAsDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      getEnvAsDuration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+			IdleTimeout:       getEnvAsDuration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+			MaxHeaderBytes:    getEnvAsInt("HTTP_MAX_HEADER_BYTES", 1<<20),
+			ShutdownDelay:     getEnvAsDuration("SHUTDOWN_DELAY", 5*time.Second),
+			ShutdownTimeout:   getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			TrustedProxies:    getEnvAsList("TRUSTED_PROXIES"),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "microblog <no-reply@localhost>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUser:     getEnv("SMTP_USER", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			FileDir:      getEnv("MAIL_FILE_DIR", "./mail"),
+		},
+		Lockout: LockoutConfig{
+			Store:           getEnv("LOGIN_LOCKOUT_STORE", "postgres"),
+			MaxFailures:     getEnvAsInt("LOGIN_MAX_FAILURES", 10),
+			IPMaxFailures:   getEnvAsInt("LOGIN_IP_MAX_FAILURES", 50),
+			LockoutDuration: getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: getEnvAsDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+			DeletionMode:        getEnv("ACCOUNT_DELETION_MODE", "anonymize"),
+			ExportDir:           getEnv("EXPORT_DIR", "./exports"),
+			ExportTTL:           getEnvAsDuration("EXPORT_TTL", 7*24*time.Hour),
 		},
 	}
+	cfg.OIDC = loadOIDCConfig(cfg.Server.PublicURL)
 	return cfg, nil
 }
 
+// loadOIDCConfig читает провайдеров из OIDC_PROVIDERS и переменных OIDC_<NAME>_*
+func loadOIDCConfig(publicURL string) OIDCConfig {
+	var oidc OIDCConfig
+	for _, name := range getEnvAsList("OIDC_PROVIDERS") {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		scopes := getEnvAsList(prefix + "SCOPES")
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+		oidc.Providers = append(oidc.Providers, OIDCProviderConfig{
+			Name:         strings.ToLower(name),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", strings.TrimRight(publicURL, "/")+"/api/auth/oidc/"+strings.ToLower(name)+"/callback"),
+			Scopes:       scopes,
+		})
+	}
+	return oidc
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -74,6 +207,34 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func (c *Config) GetDatabaseDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.Name, c.Database.SSLMode)
@@ -5,13 +5,11 @@ import (
 	"gorm.io/gorm"
 	"log"
 	"microblog/internal/config"
-	"microblog/internal/model"
 	"time"
 )
 
-var DB *gorm.DB
-
-func InitDB(cfg *config.Config) {
+// InitDB подключается к базе; схему меняют только миграции (см. MigrateUp)
+func InitDB(cfg *config.Config) *gorm.DB {
 	dsn := cfg.GetDatabaseDSN()
 
 	var db *gorm.DB
@@ -30,10 +28,5 @@ func InitDB(cfg *config.Config) {
 		log.Fatalf("Could not connect to the database after %d attempts: %v", maxAttempts, err)
 	}
 
-	err = db.AutoMigrate(&model.User{}, &model.Post{}, &model.Comment{})
-	if err != nil {
-		log.Fatal("Error in migration: ", err)
-	}
-
-	DB = db
+	return db
 }
@@ -0,0 +1,250 @@
+-- Схема на момент перехода с AutoMigrate. Все операторы идемпотентны: в базе,
+-- которую создал AutoMigrate, таблицы остаются на месте, а недостающие колонки
+-- users добавляются ниже вместе с переносом данных, который раньше делал InitDB.
+-- Колонки posts и comments с тех пор не менялись.
+
+CREATE TABLE IF NOT EXISTS users (
+    id                          bigserial PRIMARY KEY,
+    username                    varchar(100) NOT NULL,
+    password                    varchar(255) NOT NULL,
+    email                       varchar(100) NOT NULL,
+    role                        varchar(20) NOT NULL DEFAULT 'user',
+    tokens_valid_after          timestamptz,
+    email_verified              boolean NOT NULL DEFAULT false,
+    email_verification_sent_at  timestamptz,
+    pending_email               varchar(100),
+    totp_secret                 varchar(64),
+    totp_enabled                boolean NOT NULL DEFAULT false,
+    totp_last_step              bigint NOT NULL DEFAULT 0,
+    display_name                varchar(100),
+    bio                         varchar(500),
+    avatar_url                  varchar(500),
+    website                     varchar(255),
+    locale                      varchar(10),
+    created_at                  timestamptz,
+    updated_at                  timestamptz,
+    deletion_scheduled_at       timestamptz
+);
+
+-- В самой старой схеме users были только id, username, password, email и refresh-токен
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_valid_after timestamptz;
+-- До появления подтверждения email все аккаунты считались активными: существующие
+-- строки получают true, новым по умолчанию достаётся false
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified boolean NOT NULL DEFAULT true;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verification_sent_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email varchar(100);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret varchar(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name varchar(100);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bio varchar(500);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url varchar(500);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS website varchar(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale varchar(10);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at timestamptz;
+
+-- Refresh-токены теперь хранятся в сессиях, а не в таблице пользователей
+ALTER TABLE users DROP COLUMN IF EXISTS refresh_token;
+ALTER TABLE users DROP COLUMN IF EXISTS token_expiry;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id          bigserial PRIMARY KEY,
+    title       varchar(255) NOT NULL,
+    content     text NOT NULL,
+    author_id   bigint NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    CONSTRAINT fk_posts_author FOREIGN KEY (author_id) REFERENCES users (id)
+);
+
+-- Дата регистрации раньше не хранилась; для старых аккаунтов ею считается дата первого поста
+UPDATE users SET
+    created_at = COALESCE((SELECT MIN(created_at) FROM posts WHERE posts.author_id = users.id), NOW()),
+    updated_at = NOW()
+    WHERE created_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS comments (
+    id          bigserial PRIMARY KEY,
+    content     text NOT NULL,
+    post_id     bigint NOT NULL,
+    author_id   bigint NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    CONSTRAINT fk_posts_comments FOREIGN KEY (post_id) REFERENCES posts (id),
+    CONSTRAINT fk_comments_author FOREIGN KEY (author_id) REFERENCES users (id)
+);
+
+-- Сессии старого формата хранили сам refresh-токен; их проще пересоздать
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'sessions' AND column_name = 'refresh_token') THEN
+        DROP TABLE sessions;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id               bigserial PRIMARY KEY,
+    user_id          bigint NOT NULL,
+    family_id        varchar(64) NOT NULL,
+    sequence         bigint NOT NULL DEFAULT 0,
+    access_token_id  varchar(64),
+    device_name      varchar(100),
+    user_agent       varchar(500),
+    ip               varchar(45),
+    created_at       timestamptz,
+    last_used_at     timestamptz,
+    expires_at       timestamptz NOT NULL,
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_family_id ON sessions (family_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    type        varchar(50) NOT NULL,
+    ip          varchar(45),
+    user_agent  varchar(500),
+    details     text,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_type ON security_events (type);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id    varchar(64) PRIMARY KEY,
+    expires_at  timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    token_hash  varchar(64) NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    used_at     timestamptz,
+    created_at  timestamptz,
+    CONSTRAINT fk_password_reset_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    code_hash   varchar(64) NOT NULL,
+    used_at     timestamptz,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key            varchar(255) PRIMARY KEY,
+    failures       bigint NOT NULL DEFAULT 0,
+    last_failure   timestamptz NOT NULL,
+    blocked_until  timestamptz,
+    locked         boolean NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure ON login_attempts (last_failure);
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id            bigserial PRIMARY KEY,
+    user_id       bigint NOT NULL,
+    name          varchar(100) NOT NULL,
+    token_hash    varchar(64) NOT NULL,
+    token_prefix  varchar(16) NOT NULL,
+    scopes        varchar(255) NOT NULL,
+    last_used_at  timestamptz,
+    expires_at    timestamptz,
+    created_at    timestamptz,
+    CONSTRAINT fk_personal_access_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_personal_access_tokens_token_hash ON personal_access_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id             bigserial PRIMARY KEY,
+    user_id        bigint NOT NULL,
+    provider       varchar(50) NOT NULL,
+    subject        varchar(255) NOT NULL,
+    email          varchar(100),
+    last_login_at  timestamptz,
+    created_at     timestamptz,
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_provider_subject ON user_identities (provider, subject);
+
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id                  bigserial PRIMARY KEY,
+    client_id           varchar(64) NOT NULL,
+    client_secret_hash  varchar(64),
+    confidential        boolean NOT NULL DEFAULT false,
+    name                varchar(100) NOT NULL,
+    redirect_uris       text NOT NULL,
+    scopes              varchar(255) NOT NULL,
+    owner_id            bigint NOT NULL,
+    created_at          timestamptz,
+    CONSTRAINT fk_oauth_clients_owner FOREIGN KEY (owner_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_clients_client_id ON oauth_clients (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_owner_id ON oauth_clients (owner_id);
+
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id              bigserial PRIMARY KEY,
+    code_hash       varchar(64) NOT NULL,
+    client_id       bigint NOT NULL,
+    user_id         bigint NOT NULL,
+    redirect_uri    text NOT NULL,
+    scopes          varchar(255) NOT NULL,
+    code_challenge  varchar(128) NOT NULL,
+    expires_at      timestamptz NOT NULL,
+    used_at         timestamptz,
+    created_at      timestamptz,
+    CONSTRAINT fk_oauth_authorization_codes_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id),
+    CONSTRAINT fk_oauth_authorization_codes_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_authorization_codes_code_hash ON oauth_authorization_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_client_id ON oauth_authorization_codes (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_user_id ON oauth_authorization_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS oauth_refresh_tokens (
+    id          bigserial PRIMARY KEY,
+    token_hash  varchar(64) NOT NULL,
+    client_id   bigint NOT NULL,
+    user_id     bigint NOT NULL,
+    scopes      varchar(255) NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    created_at  timestamptz,
+    CONSTRAINT fk_oauth_refresh_tokens_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id),
+    CONSTRAINT fk_oauth_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_token_hash ON oauth_refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_client_id ON oauth_refresh_tokens (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_refresh_tokens_user_id ON oauth_refresh_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id            bigserial PRIMARY KEY,
+    user_id       bigint NOT NULL,
+    status        varchar(20) NOT NULL,
+    file_path     varchar(500),
+    file_size     bigint,
+    started_at    timestamptz,
+    completed_at  timestamptz,
+    expires_at    timestamptz,
+    created_at    timestamptz,
+    CONSTRAINT fk_data_exports_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id);
+CREATE INDEX IF NOT EXISTS idx_data_exports_status ON data_exports (status);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports (expires_at);
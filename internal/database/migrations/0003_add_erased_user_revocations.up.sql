@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS erased_user_revocations (
+    username     varchar(100) PRIMARY KEY,
+    valid_after  timestamptz NOT NULL,
+    expires_at   timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_erased_user_revocations_expires_at ON erased_user_revocations (expires_at);
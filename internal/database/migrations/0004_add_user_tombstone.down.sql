@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_tombstone;
+ALTER TABLE users DROP COLUMN IF EXISTS tombstone;
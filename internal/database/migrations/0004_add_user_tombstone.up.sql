@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tombstone boolean NOT NULL DEFAULT false;
+-- Раньше служебный пользователь определялся по имени; пароль "!" бывает только у него
+UPDATE users SET tombstone = true WHERE username = 'deleted' AND password = '!';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tombstone ON users (tombstone) WHERE tombstone;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS oauth_access_tokens (
+    id          bigserial PRIMARY KEY,
+    token_id    varchar(64) NOT NULL,
+    client_id   bigint NOT NULL,
+    user_id     bigint NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    created_at  timestamptz,
+    CONSTRAINT fk_oauth_access_tokens_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id),
+    CONSTRAINT fk_oauth_access_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_access_tokens_token_id ON oauth_access_tokens (token_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_access_tokens_client_id ON oauth_access_tokens (client_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_access_tokens_user_id ON oauth_access_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_oauth_access_tokens_expires_at ON oauth_access_tokens (expires_at);
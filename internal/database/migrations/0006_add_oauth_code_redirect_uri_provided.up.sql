@@ -0,0 +1 @@
+ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS redirect_uri_provided boolean NOT NULL DEFAULT false;
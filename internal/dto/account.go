@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type Session struct {
+	ID         int64     `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Сессия, из которой сделан запрос
+	Current bool `json:"current"`
+}
+
+type PersonalAccessToken struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      string     `json:"scopes"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type OAuthClient struct {
+	ID           int64     `json:"id"`
+	ClientID     string    `json:"client_id"`
+	Confidential bool      `json:"confidential"`
+	Name         string    `json:"name"`
+	RedirectURIs string    `json:"redirect_uris"`
+	Scopes       string    `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type DataExport struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"`
+	FileSize    int64      `json:"file_size"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func NewSessions(sessions []model.Session, currentID int64) []Session {
+	result := make([]Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = Session{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		}
+	}
+	return result
+}
+
+func NewPersonalAccessToken(token *model.PersonalAccessToken) *PersonalAccessToken {
+	return &PersonalAccessToken{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		LastUsedAt:  token.LastUsedAt,
+		ExpiresAt:   token.ExpiresAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+func NewPersonalAccessTokens(tokens []model.PersonalAccessToken) []PersonalAccessToken {
+	result := make([]PersonalAccessToken, len(tokens))
+	for i := range tokens {
+		result[i] = *NewPersonalAccessToken(&tokens[i])
+	}
+	return result
+}
+
+func NewOAuthClient(client *model.OAuthClient) *OAuthClient {
+	return &OAuthClient{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Confidential: client.Confidential,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+func NewOAuthClients(clients []model.OAuthClient) []OAuthClient {
+	result := make([]OAuthClient, len(clients))
+	for i := range clients {
+		result[i] = *NewOAuthClient(&clients[i])
+	}
+	return result
+}
+
+func NewDataExport(export *model.DataExport) *DataExport {
+	return &DataExport{
+		ID:          export.ID,
+		Status:      export.Status,
+		FileSize:    export.FileSize,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+		CreatedAt:   export.CreatedAt,
+	}
+}
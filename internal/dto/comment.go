@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type Comment struct {
+	ID        int64      `json:"id"`
+	Content   string     `json:"content"`
+	PostID    int64      `json:"post_id"`
+	AuthorID  int64      `json:"author_id"`
+	Author    PublicUser `json:"author"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewComment(comment *model.Comment) *Comment {
+	return &Comment{
+		ID:        comment.ID,
+		Content:   comment.Content,
+		PostID:    comment.PostID,
+		AuthorID:  comment.AuthorID,
+		Author:    NewPublicUser(&comment.Author),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+// NewComments сохраняет nil для nil, чтобы у поста без загруженных комментариев поле опускалось
+func NewComments(comments []model.Comment) []Comment {
+	if comments == nil {
+		return nil
+	}
+	result := make([]Comment, len(comments))
+	for i := range comments {
+		result[i] = *NewComment(&comments[i])
+	}
+	return result
+}
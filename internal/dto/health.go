@@ -0,0 +1,18 @@
+package dto
+
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+// Health — ответ /healthz и /readyz; Components есть только у проверки готовности
+type Health struct {
+	Status     string                     `json:"status"`
+	Components map[string]HealthComponent `json:"components,omitempty"`
+}
+
+// HealthComponent — состояние одной зависимости. /readyz доступен без авторизации,
+// поэтому причины сбоя в ответ не попадают, только в лог сервера.
+type HealthComponent struct {
+	Status string `json:"status"`
+}
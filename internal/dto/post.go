@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type Post struct {
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	Content       string     `json:"content"`
+	AuthorID      int64      `json:"author_id"`
+	Author        PublicUser `json:"author"`
+	Comments      []Comment  `json:"comments,omitempty"`
+	CommentsCount int64      `json:"comments_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func NewPost(post *model.Post) *Post {
+	return &Post{
+		ID:            post.ID,
+		Title:         post.Title,
+		Content:       post.Content,
+		AuthorID:      post.AuthorID,
+		Author:        NewPublicUser(&post.Author),
+		Comments:      NewComments(post.Comments),
+		CommentsCount: post.CommentsCount,
+		CreatedAt:     post.CreatedAt,
+		UpdatedAt:     post.UpdatedAt,
+	}
+}
+
+func NewPosts(posts []model.Post) []Post {
+	result := make([]Post, len(posts))
+	for i := range posts {
+		result[i] = *NewPost(&posts[i])
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+// Ответы API строятся только из типов этого пакета: model.User хранит хеш пароля
+// и служебные поля, поэтому напрямую в JSON не отдаётся
+
+// User — данные аккаунта для самого владельца
+type User struct {
+	ID                  int64      `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	EmailVerified       bool       `json:"email_verified"`
+	TOTPEnabled         bool       `json:"totp_enabled"`
+	DisplayName         string     `json:"display_name"`
+	Bio                 string     `json:"bio"`
+	AvatarURL           string     `json:"avatar_url"`
+	Website             string     `json:"website"`
+	Locale              string     `json:"locale"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+// PublicUser — автор поста или комментария, виден всем
+type PublicUser struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+// UserProfile — публичный профиль, без email и настроек безопасности
+type UserProfile struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url"`
+	Website     string    `json:"website"`
+	PostsCount  int64     `json:"posts_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewUser(user *model.User) *User {
+	return &User{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		Role:                user.Role,
+		EmailVerified:       user.EmailVerified,
+		TOTPEnabled:         user.TOTPEnabled,
+		DisplayName:         user.DisplayName,
+		Bio:                 user.Bio,
+		AvatarURL:           user.AvatarURL,
+		Website:             user.Website,
+		Locale:              user.Locale,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
+
+func NewPublicUser(user *model.User) PublicUser {
+	return PublicUser{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+	}
+}
+
+func NewUserProfile(user *model.User, postsCount int64) *UserProfile {
+	return &UserProfile{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		Website:     user.Website,
+		PostsCount:  postsCount,
+		CreatedAt:   user.CreatedAt,
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/mailer"
+	"microblog/internal/model"
+	"microblog/internal/service"
+	"microblog/internal/util"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	accessToken, err := h.accounts.ChangePassword(user, req.CurrentPassword, req.NewPassword, c.GetInt64("session_id"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventPasswordChanged, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Password changed successfully",
+		"access_token": accessToken,
+	})
+}
+
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	if !util.IsValidEmail(req.NewEmail) {
+		apierr.Fields(c, apierr.Invalid("new_email", "email"))
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	token, err := h.accounts.RequestEmailChange(user, req.NewEmail, req.Password)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	link := h.mail.Link("/confirm-email-change", url.Values{"token": {token}})
+	err = h.mail.Send(mailer.Message{
+		To:      req.NewEmail,
+		Subject: "Confirm your new email",
+		Body: fmt.Sprintf("Hi %s,\n\nTo use this address for your microblog account, open the link below:\n\n%s\n\n"+
+			"The link is valid for 24 hours. If you did not request this change, ignore this message.\n",
+			user.Username, link),
+	})
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Confirmation email sent to the new address",
+	})
+}
+
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	oldEmail, accessToken, err := h.accounts.ConfirmEmailChange(user, req.Token, c.GetInt64("session_id"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventEmailChanged, fmt.Sprintf("%s -> %s", oldEmail, user.Email))
+
+	err = h.mail.Send(mailer.Message{
+		To:      oldEmail,
+		Subject: "Your email was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe email address of your microblog account was changed to %s.\n"+
+			"If you did not do this, reset your password immediately.\n",
+			user.Username, user.Email),
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %d about email change: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Email changed successfully",
+		"access_token": accessToken,
+	})
+}
+
+type DeleteAccountRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// DeleteAccount назначает удаление аккаунта; до истечения срока его можно отменить
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if err := h.accounts.VerifyPassword(user, req.Password); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	if user.TOTPEnabled {
+		ok, err := h.verifySecondFactor(c, user, req.Code, req.RecoveryCode)
+		if err != nil {
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+			return
+		}
+		if !ok {
+			apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidMFACode)
+			return
+		}
+	}
+
+	scheduledAt, err := h.accounts.ScheduleDeletion(user)
+	if errors.Is(err, service.ErrDeletionScheduled) {
+		apierr.Write(c, apierr.New(http.StatusConflict, apierr.CodeDeletionScheduled).
+			With("deletion_scheduled_at", user.DeletionScheduledAt))
+		return
+	}
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventDeletionScheduled, scheduledAt.Format(time.RFC3339))
+
+	err = h.mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and personal data will be permanently deleted on %s.\n\n"+
+			"If you change your mind, sign in and cancel the deletion before then. "+
+			"If you did not request this, sign in and cancel it, then change your password.\n",
+			user.Username, scheduledAt.UTC().Format("2 January 2006 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("Failed to send deletion notice to user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":               "Account deletion scheduled",
+		"deletion_scheduled_at": scheduledAt,
+	})
+}
+
+func (h *AuthHandler) CancelAccountDeletion(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if err := h.accounts.CancelDeletion(user); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventDeletionCanceled, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deletion canceled",
+	})
+}
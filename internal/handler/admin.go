@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/model"
+	"net/http"
+)
+
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	user, err := h.users.GetUserByUsername(c.Param("username"))
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeUserNotFound)
+		return
+	}
+
+	if err := h.limiter.Unlock(user.Email); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventAccountUnlocked, "by "+c.GetString("username"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked successfully",
+	})
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+func (h *AuthHandler) SetUserRole(c *gin.Context) {
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(c.Param("username"))
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeUserNotFound)
+		return
+	}
+
+	// Иначе последний администратор может случайно лишить себя доступа
+	if user.Username == c.GetString("username") {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeOwnRoleChange)
+		return
+	}
+
+	if err := h.users.UpdateUserRole(user.ID, req.Role); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	// Роль записана в access-токены, поэтому старые токены нужно погасить
+	if err := h.revoker.RevokeUserTokens(user.Username); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventRoleChanged, fmt.Sprintf("%s -> %s by %s", user.Role, req.Role, c.GetString("username")))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+	})
+}
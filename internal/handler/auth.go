@@ -1,30 +1,102 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"log"
+	"math"
+	"microblog/internal/account"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/lockout"
+	"microblog/internal/mailer"
 	"microblog/internal/model"
+	"microblog/internal/oidc"
 	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"microblog/internal/service"
 	"microblog/internal/util"
+	"microblog/internal/worker"
 	"net/http"
-	"regexp"
+	"strconv"
+	"strings"
 )
 
+// AuthDependencies — хранилища и службы, с которыми работает AuthHandler
+type AuthDependencies struct {
+	Users          repository.UserRepository
+	SecurityEvents repository.SecurityEventRepository
+	TOTP           repository.TOTPRepository
+	Tokens         repository.PersonalAccessTokenRepository
+	Identities     repository.UserIdentityRepository
+	DataExports    repository.DataExportRepository
+
+	Sessions  *service.SessionService
+	Accounts  *service.AccountService
+	Revoker   *revocation.Revoker
+	Limiter   *lockout.Limiter
+	Mail      *mailer.Sender
+	Providers *oidc.Registry
+	Lifecycle *account.Jobs
+	Worker    *worker.Pool
+}
+
+// AuthHandler обслуживает вход, сессии и управление собственным аккаунтом
+type AuthHandler struct {
+	users          repository.UserRepository
+	securityEvents repository.SecurityEventRepository
+	totp           repository.TOTPRepository
+	tokens         repository.PersonalAccessTokenRepository
+	identities     repository.UserIdentityRepository
+	dataExports    repository.DataExportRepository
+
+	sessions  *service.SessionService
+	accounts  *service.AccountService
+	revoker   *revocation.Revoker
+	limiter   *lockout.Limiter
+	mail      *mailer.Sender
+	providers *oidc.Registry
+	lifecycle *account.Jobs
+	worker    *worker.Pool
+}
+
+func NewAuthHandler(deps AuthDependencies) *AuthHandler {
+	return &AuthHandler{
+		users:          deps.Users,
+		securityEvents: deps.SecurityEvents,
+		totp:           deps.TOTP,
+		tokens:         deps.Tokens,
+		identities:     deps.Identities,
+		dataExports:    deps.DataExports,
+		sessions:       deps.Sessions,
+		accounts:       deps.Accounts,
+		revoker:        deps.Revoker,
+		limiter:        deps.Limiter,
+		mail:           deps.Mail,
+		providers:      deps.Providers,
+		lifecycle:      deps.Lifecycle,
+		worker:         deps.Worker,
+	}
+}
+
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,min=3"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name" binding:"max=100"`
 }
 
 type LoginResponse struct {
-	AccessToken  string      `json:"access_token"`
-	RefreshToken string      `json:"refresh_token"`
-	User         *model.User `json:"user"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	SessionID    int64     `json:"session_id"`
+	User         *dto.User `json:"user"`
 }
 
 type RefreshRequest struct {
@@ -36,204 +108,209 @@ type RefreshResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-var (
-	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,}$`)
-	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-)
-
-func Register(c *gin.Context) {
+func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
-	if len(req.Username) < 3 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Username must be at least 3 characters long",
-		})
+	if !util.IsValidUsername(req.Username) {
+		apierr.Fields(c, apierr.Invalid("username", "username"))
 		return
 	}
 
-	if !usernameRegex.MatchString(req.Username) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Username must contain only Latin letters, numbers, underscores, and hyphens",
-		})
+	if !util.IsValidEmail(req.Email) {
+		apierr.Fields(c, apierr.Invalid("email", "email"))
 		return
 	}
 
-	if !emailRegex.MatchString(req.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid email format",
-		})
+	if strings.EqualFold(req.Username, model.DeletedUsername) {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeUsernameTaken)
 		return
 	}
 
-	if user, _ := repository.GetUserByUsername(req.Username); user != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Username already taken",
-		})
+	if user, _ := h.users.GetUserByUsername(req.Username); user != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeUsernameTaken)
 		return
 	}
 
-	if user, _ := repository.GetUserByEmail(req.Email); user != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Email already registered",
-		})
+	if user, _ := h.users.GetUserByEmail(req.Email); user != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeEmailTaken)
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to process password",
-		})
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	user, err := repository.CreateUser(&model.User{
+	user, err := h.users.CreateUser(&model.User{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: string(hashedPassword),
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create user",
-		})
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	user.Password = ""
+	// Регистрация не должна падать из-за почты: письмо можно запросить повторно
+	if err := h.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User registered successfully",
-		"user":    user,
+		"user":    dto.NewUser(user),
 	})
 }
 
-func Login(c *gin.Context) {
+func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
-	user, err := repository.GetUserByEmail(req.Email)
+	// Блокировку проверяем до bcrypt, чтобы подбор не нагружал сервер
+	if err := h.limiter.Check(req.Email, c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+
+	user, err := h.users.GetUserByEmail(req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid credentials",
-		})
+		h.registerLoginFailure(c, nil, req.Email)
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidCredentials)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid credentials",
-		})
+		h.registerLoginFailure(c, user, req.Email)
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidCredentials)
 		return
 	}
 
-	accessToken, err := util.GenerateToken(user.Username)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate access token",
-		})
+	h.beginLogin(c, user, req.DeviceName)
+}
+
+// beginLogin вызывается после проверки пароля или внешнего провайдера
+func (h *AuthHandler) beginLogin(c *gin.Context, user *model.User, deviceName string) {
+	if user.DisabledAt != nil {
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeAccountDisabled)
 		return
 	}
 
-	refreshToken, err := util.GenerateRefreshToken(user.Username)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate refresh token",
+	// С включённой 2FA вместо токенов выдаётся короткоживущий токен проверки
+	if user.TOTPEnabled {
+		mfaToken, err := util.GenerateActionToken(util.PurposeMFAChallenge, user.Username, "", deviceName, mfaChallengeTTL)
+		if err != nil {
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+			return
+		}
+
+		c.JSON(http.StatusOK, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
 		})
 		return
 	}
 
-	if err := repository.UpdateUserRefreshToken(user.Username, refreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to save refresh token",
-		})
+	h.completeLogin(c, user, deviceName)
+}
+
+func (h *AuthHandler) completeLogin(c *gin.Context, user *model.User, deviceName string) {
+	session, accessToken, refreshToken, err := h.sessions.Start(user, device(c, deviceName))
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	user.Password = ""
+	if err := h.limiter.RecordSuccess(user.Email); err != nil {
+		log.Printf("Failed to reset login attempts for user %d: %v", user.ID, err)
+	}
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventLoginSucceeded, deviceName)
+
 	c.JSON(http.StatusOK, LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		User:         user,
+		SessionID:    session.ID,
+		User:         dto.NewUser(user),
 	})
 }
 
-func RefreshToken(c *gin.Context) {
-	var req RefreshRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+func respondLoginBlocked(c *gin.Context, err error) {
+	var blocked *lockout.BlockedError
+	if !errors.As(err, &blocked) {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	_, err := util.ValidateRefreshToken(req.RefreshToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid refresh token",
-		})
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	if blocked.AccountLocked {
+		apierr.Respond(c, http.StatusLocked, apierr.CodeAccountLocked)
 		return
 	}
+	apierr.Respond(c, http.StatusTooManyRequests, apierr.CodeTooManyAttempts)
+}
 
-	user, err := repository.GetUserByRefreshToken(req.RefreshToken)
+// registerLoginFailure учитывает неудачу и для несуществующих адресов, чтобы ответы не различались
+func (h *AuthHandler) registerLoginFailure(c *gin.Context, user *model.User, email string) {
+	locked, err := h.limiter.RecordFailure(email, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Refresh token not found or expired",
-		})
-		return
+		log.Printf("Failed to record login failure: %v", err)
 	}
 
-	newAccessToken, err := util.GenerateToken(user.Username)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate new access token",
-		})
+	if user == nil {
 		return
 	}
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventLoginFailed, "")
+	if locked {
+		h.recordSecurityEvent(c, user.ID, model.SecurityEventAccountLocked, "")
+	}
+}
 
-	newRefreshToken, err := util.GenerateRefreshToken(user.Username)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate new refresh token",
-		})
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
 		return
 	}
 
-	if err := repository.UpdateUserRefreshToken(user.Username, newRefreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update refresh token",
-		})
+	accessToken, refreshToken, err := h.sessions.Refresh(req.RefreshToken)
+	if err != nil {
+		// Предъявлен уже использованный токен: семейство отозвано, владельцу оставляем след
+		var reuse *service.RefreshReuseError
+		switch {
+		case errors.As(err, &reuse):
+			h.recordSecurityEvent(c, reuse.Session.UserID, model.SecurityEventRefreshTokenReuse, reuse.Error())
+			apierr.Respond(c, http.StatusUnauthorized, apierr.CodeRefreshTokenReuse)
+		case errors.Is(err, service.ErrInvalidToken):
+			apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidOrExpiredToken)
+		default:
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, RefreshResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	})
 }
-func Logout(c *gin.Context) {
-	username, exists := c.Get("username")
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	if err := repository.ClearUserRefreshToken(username.(string)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to logout",
-		})
+	if err := h.sessions.Logout(sessionID.(int64), c.GetString("token_id"), c.GetTime("token_expires_at")); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
@@ -2,12 +2,24 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
-	"microblog/internal/model"
-	"microblog/internal/repository"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/service"
 	"net/http"
 	"strconv"
 )
 
+// CommentHandler обслуживает комментарии к постам
+type CommentHandler struct {
+	comments *service.CommentService
+	posts    *service.PostService
+	users    *service.UserService
+}
+
+func NewCommentHandler(comments *service.CommentService, posts *service.PostService, users *service.UserService) *CommentHandler {
+	return &CommentHandler{comments: comments, posts: posts, users: users}
+}
+
 type CreateCommentRequest struct {
 	Content string `json:"content" binding:"required,min=1"`
 }
@@ -16,77 +28,49 @@ type UpdateCommentRequest struct {
 	Content string `json:"content" binding:"required,min=1"`
 }
 
-func CreateComment(c *gin.Context) {
+func (h *CommentHandler) CreateComment(c *gin.Context) {
 	postIDStr := c.Param("id")
 	postID, err := strconv.ParseInt(postIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
 	var req CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	_, err = repository.GetPostByID(postID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
-		return
-	}
-
-	comment := &model.Comment{
-		Content:  req.Content,
-		PostID:   postID,
-		AuthorID: user.ID,
-	}
-
-	createdComment, err := repository.CreateComment(comment)
+	createdComment, err := h.comments.Create(user, postID, req.Content)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create comment",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	createdComment.Author.Password = ""
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Comment created successfully",
-		"comment": createdComment,
+		"comment": dto.NewComment(createdComment),
 	})
 }
 
-func GetCommentsByPost(c *gin.Context) {
+func (h *CommentHandler) GetCommentsByPost(c *gin.Context) {
 	postIDStr := c.Param("id")
 	postID, err := strconv.ParseInt(postIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
@@ -103,38 +87,22 @@ func GetCommentsByPost(c *gin.Context) {
 		offset = 0
 	}
 
-	_, err = repository.GetPostByID(postID)
+	comments, err := h.comments.ListByPost(postID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	comments, err := repository.GetCommentsByPostID(postID, limit, offset)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch comments",
-		})
-		return
-	}
-
-	for i := range comments {
-		comments[i].Author.Password = ""
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"comments": comments,
+		"comments": dto.NewComments(comments),
 	})
 }
 
-func GetPostWithComments(c *gin.Context) {
+func (h *CommentHandler) GetPostWithComments(c *gin.Context) {
 	postIDStr := c.Param("id")
 	postID, err := strconv.ParseInt(postIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
@@ -151,135 +119,77 @@ func GetPostWithComments(c *gin.Context) {
 		commentOffset = 0
 	}
 
-	post, err := repository.GetPostByIDWithComments(postID, commentLimit, commentOffset)
+	post, err := h.posts.GetWithComments(postID, commentLimit, commentOffset)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	post.Author.Password = ""
-
 	c.JSON(http.StatusOK, gin.H{
-		"post": post,
+		"post": dto.NewPost(post),
 	})
 }
 
-func UpdateComment(c *gin.Context) {
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := strconv.ParseInt(commentIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid comment ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
 	var req UpdateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	comment, err := repository.GetCommentByID(commentID)
+	result, err := h.comments.Update(actingAs(c, user), commentID, req.Content)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Comment not found",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	if comment.AuthorID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You can only update your own comments",
-		})
-		return
-	}
-
-	updatedComment := &model.Comment{
-		Content: req.Content,
-	}
-
-	result, err := repository.UpdateComment(commentID, updatedComment)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update comment",
-		})
-		return
-	}
-
-	result.Author.Password = ""
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Comment updated successfully",
-		"comment": result,
+		"comment": dto.NewComment(result),
 	})
 }
 
-func DeleteComment(c *gin.Context) {
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := strconv.ParseInt(commentIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid comment ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
-		return
-	}
-
-	user, err := repository.GetUserByUsername(username.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	comment, err := repository.GetCommentByID(commentID)
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Comment not found",
-		})
-		return
-	}
-
-	if comment.AuthorID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You can only delete your own comments",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	if err := repository.DeleteComment(commentID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete comment",
-		})
+	if err := h.comments.Delete(actingAs(c, user), commentID); err != nil {
+		respondServiceError(c, err)
 		return
 	}
 
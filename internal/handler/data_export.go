@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/model"
+	"microblog/internal/util"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestDataExport ставит в очередь сборку архива со всеми данными пользователя
+func (h *AuthHandler) RequestDataExport(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if latest, _ := h.dataExports.GetLatestDataExport(user.ID); latest != nil &&
+		(latest.Status == model.DataExportPending || latest.Status == model.DataExportProcessing) {
+		apierr.Write(c, apierr.New(http.StatusConflict, apierr.CodeExportInProgress).
+			With("export", dto.NewDataExport(latest)))
+		return
+	}
+
+	export, err := h.dataExports.CreateDataExport(&model.DataExport{
+		UserID: user.ID,
+		Status: model.DataExportPending,
+	})
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventDataExported, "")
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Data export requested; we will email you when the archive is ready",
+		"export":  dto.NewDataExport(export),
+	})
+}
+
+// GetDataExport возвращает состояние последней выгрузки и свежую ссылку, если архив готов
+func (h *AuthHandler) GetDataExport(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	export, err := h.dataExports.GetLatestDataExport(user.ID)
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeExportNotFound)
+		return
+	}
+
+	response := gin.H{
+		"export": dto.NewDataExport(export),
+	}
+	if export.Status == model.DataExportReady {
+		link, err := h.lifecycle.ExportDownloadLink(export, user.Username)
+		if err != nil {
+			log.Printf("Failed to create download link for data export %d: %v", export.ID, err)
+		} else {
+			response["download_url"] = link
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadDataExport отдаёт архив по ссылке из письма; ссылка сама по себе служит авторизацией
+func (h *AuthHandler) DownloadDataExport(c *gin.Context) {
+	claims, err := util.ValidateActionToken(c.Query("token"), util.PurposeDataExport)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	id, err := strconv.ParseInt(claims.Data, 10, 64)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	export, err := h.dataExports.GetDataExportByID(id)
+	if err != nil || export.Status != model.DataExportReady || export.ExpiresAt == nil || !time.Now().Before(*export.ExpiresAt) {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeExportNotFound)
+		return
+	}
+
+	// Имя могло смениться владельцем или перейти к другому аккаунту после удаления
+	user, err := h.users.GetUserByUsername(claims.Subject)
+	if err != nil || user.ID != export.UserID {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeExportNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(export.FilePath, "microblog-"+user.Username+"-"+export.CreatedAt.UTC().Format("20060102")+".zip")
+}
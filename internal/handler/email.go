@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/mailer"
+	"microblog/internal/model"
+	"microblog/internal/util"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	emailVerificationTTL      = 24 * time.Hour
+	emailVerificationCooldown = time.Minute
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *AuthHandler) sendVerificationEmail(user *model.User) error {
+	token, err := util.GenerateActionToken(util.PurposeEmailVerification, user.Username, user.Email, "", emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := h.mail.Link("/verify-email", url.Values{"token": {token}})
+	err = h.mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link is valid for 24 hours. If you did not create an account, ignore this message.\n",
+			user.Username, link),
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.users.SetUserEmailVerificationSentAt(user.ID, time.Now())
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	claims, err := util.ValidateActionToken(req.Token, util.PurposeEmailVerification)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(claims.Subject)
+	if err != nil || user.Email != claims.Email {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	if user.EmailVerified {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeEmailAlreadyVerified)
+		return
+	}
+
+	if err := h.users.MarkUserEmailVerified(user.ID); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if user.EmailVerified {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeEmailAlreadyVerified)
+		return
+	}
+
+	if user.EmailVerificationSentAt != nil && time.Since(*user.EmailVerificationSentAt) < emailVerificationCooldown {
+		apierr.Respond(c, http.StatusTooManyRequests, apierr.CodeVerificationEmailSent)
+		return
+	}
+
+	if err := h.sendVerificationEmail(user); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Verification email sent",
+	})
+}
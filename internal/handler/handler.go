@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/model"
+	"microblog/internal/service"
+	"net/http"
+)
+
+// Handlers собирает обработчики всех разделов API для роутера
+type Handlers struct {
+	Posts    *PostHandler
+	Comments *CommentHandler
+	Users    *UserHandler
+	Auth     *AuthHandler
+	OAuth    *OAuthHandler
+	Health   *HealthHandler
+}
+
+// respondServiceError переводит ошибки бизнес-правил из service в ответы API
+func respondServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound):
+		apierr.Respond(c, http.StatusNotFound, apierr.CodePostNotFound)
+	case errors.Is(err, service.ErrCommentNotFound):
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeCommentNotFound)
+	case errors.Is(err, service.ErrUserNotFound):
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeUserNotFound)
+	case errors.Is(err, service.ErrNotOwner):
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeNotOwner)
+	case errors.Is(err, service.ErrEmailNotVerified):
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeEmailNotVerified)
+	case errors.Is(err, service.ErrSessionNotFound):
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeSessionNotFound)
+	case errors.Is(err, service.ErrInvalidToken):
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidOrExpiredToken)
+	case errors.Is(err, service.ErrIncorrectPassword):
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeIncorrectPassword)
+	case errors.Is(err, service.ErrEmailTaken):
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeEmailTaken)
+	case errors.Is(err, service.ErrEmailUnchanged):
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeEmailUnchanged)
+	case errors.Is(err, service.ErrDeletionNotScheduled):
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeDeletionNotScheduled)
+	default:
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+	}
+}
+
+// actingAs подставляет роль из токена запроса: приложения и персональные токены действуют
+// от имени пользователя, но без полномочий модератора или администратора
+func actingAs(c *gin.Context, user *model.User) *model.User {
+	acting := *user
+	acting.Role = c.GetString("role")
+	return &acting
+}
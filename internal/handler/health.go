@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/dto"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Столько ждём каждую зависимость; зависшая база не должна вешать проверку готовности
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthCheck проверяет одну зависимость; ошибка означает, что экземпляр не готов принимать трафик
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler обслуживает проверки живости и готовности для оркестратора и балансировщика
+type HealthHandler struct {
+	checks   []HealthCheck
+	draining atomic.Bool
+}
+
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Drain переводит готовность в fail, чтобы балансировщик перестал слать запросы до остановки сервера
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Healthz отвечает, пока процесс способен обрабатывать запросы; зависимости не проверяет
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.Health{Status: dto.HealthOK})
+}
+
+// Readyz проверяет зависимости параллельно и возвращает 503, если хотя бы одна не в порядке
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, dto.Health{
+			Status: dto.HealthFail,
+			Components: map[string]dto.HealthComponent{
+				"server": {Status: dto.HealthFail},
+			},
+		})
+		return
+	}
+
+	response := dto.Health{
+		Status:     dto.HealthOK,
+		Components: make(map[string]dto.HealthComponent, len(h.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check.Check(ctx)
+			component := dto.HealthComponent{Status: dto.HealthOK}
+			if err != nil {
+				log.Printf("Readiness check %s failed after %s: %v", check.Name, time.Since(started), err)
+				component.Status = dto.HealthFail
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Components[check.Name] = component
+			if err != nil {
+				response.Status = dto.HealthFail
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if response.Status != dto.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
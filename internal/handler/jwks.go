@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"microblog/internal/util"
+	"net/http"
+)
+
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(util.JWKSCacheTTL.Seconds())))
+	c.JSON(http.StatusOK, gin.H{
+		"keys": util.JWKS(),
+	})
+}
@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"microblog/internal/util"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const authorizationCodeTTL = 5 * time.Minute
+
+// OAuthHandler обслуживает сторонние приложения: регистрацию, согласие и выдачу токенов
+type OAuthHandler struct {
+	users   repository.UserRepository
+	oauth   repository.OAuthRepository
+	revoker *revocation.Revoker
+}
+
+func NewOAuthHandler(users repository.UserRepository, oauth repository.OAuthRepository, revoker *revocation.Revoker) *OAuthHandler {
+	return &OAuthHandler{users: users, oauth: oauth, revoker: revoker}
+}
+
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,min=1,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=10,dive,required,max=500"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,dive,oneof=read posts:write comments:write"`
+	// Конфиденциальные клиенты (серверные приложения) получают секрет
+	Confidential bool `json:"confidential"`
+}
+
+// AuthorizeRequest — параметры запроса авторизации из RFC 6749, которые приложение передаёт в адресе
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+type ConsentRequest struct {
+	AuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+type authorization struct {
+	Client      *model.OAuthClient
+	RedirectURI string
+	Scopes      []string
+}
+
+func (h *OAuthHandler) CreateOAuthClient(c *gin.Context) {
+	var req CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	var invalid []apierr.FieldError
+	for i, uri := range req.RedirectURIs {
+		if !isValidRedirectURI(uri) {
+			invalid = append(invalid, apierr.Invalid("redirect_uris["+strconv.Itoa(i)+"]", "redirect_uri"))
+		}
+	}
+	if len(invalid) > 0 {
+		apierr.Fields(c, invalid...)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	clientID, err := util.GenerateRandomString(16)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	client := &model.OAuthClient{
+		ClientID:     clientID,
+		Confidential: req.Confidential,
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+		OwnerID:      user.ID,
+	}
+
+	var clientSecret string
+	if req.Confidential {
+		clientSecret, err = util.GenerateRandomString(32)
+		if err != nil {
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+			return
+		}
+		client.ClientSecretHash = util.HashToken(clientSecret)
+	}
+
+	createdClient, err := h.oauth.CreateOAuthClient(client)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	// Секрет показывается только один раз
+	response := gin.H{
+		"message": "Client registered successfully",
+		"client":  dto.NewOAuthClient(createdClient),
+	}
+	if clientSecret != "" {
+		response["client_secret"] = clientSecret
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *OAuthHandler) GetOAuthClients(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	clients, err := h.oauth.GetOAuthClientsByOwnerID(user.ID)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"clients": dto.NewOAuthClients(clients),
+	})
+}
+
+func (h *OAuthHandler) DeleteOAuthClient(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	client, err := h.oauth.GetOAuthClientByID(id)
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeOAuthClientNotFound)
+		return
+	}
+
+	if client.OwnerID != user.ID {
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeNotOwner)
+		return
+	}
+
+	if err := h.oauth.DeleteOAuthClient(id); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Client deleted successfully",
+	})
+}
+
+// GetOAuthConsent проверяет запрос авторизации и возвращает данные для экрана согласия
+func (h *OAuthHandler) GetOAuthConsent(c *gin.Context) {
+	var req AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	auth, problem := h.validateAuthorizeRequest(&req)
+	if problem != nil {
+		apierr.Write(c, problem)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"client": gin.H{
+			"client_id": auth.Client.ClientID,
+			"name":      auth.Client.Name,
+		},
+		"scopes":       auth.Scopes,
+		"redirect_uri": auth.RedirectURI,
+		"state":        req.State,
+	})
+}
+
+// ApproveOAuthConsent фиксирует решение пользователя и возвращает адрес,
+// на который нужно вернуть его в приложение
+func (h *OAuthHandler) ApproveOAuthConsent(c *gin.Context) {
+	var req ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	auth, problem := h.validateAuthorizeRequest(&req.AuthorizeRequest)
+	if problem != nil {
+		apierr.Write(c, problem)
+		return
+	}
+
+	if !req.Approve {
+		c.JSON(http.StatusOK, gin.H{
+			"redirect_to": redirectWithParams(auth.RedirectURI, url.Values{
+				"error": {"access_denied"},
+				"state": {req.State},
+			}),
+		})
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	code, err := util.GenerateRandomString(32)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	err = h.oauth.CreateOAuthAuthorizationCode(&model.OAuthAuthorizationCode{
+		CodeHash:            util.HashToken(code),
+		ClientID:            auth.Client.ID,
+		UserID:              user.ID,
+		RedirectURI:         auth.RedirectURI,
+		RedirectURIProvided: req.RedirectURI != "",
+		Scopes:              strings.Join(auth.Scopes, " "),
+		CodeChallenge:       req.CodeChallenge,
+		ExpiresAt:           time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"redirect_to": redirectWithParams(auth.RedirectURI, url.Values{
+			"code":  {code},
+			"state": {req.State},
+		}),
+	})
+}
+
+// validateAuthorizeRequest проверяет запрос авторизации. Пока адрес возврата не проверен,
+// ошибка показывается только пользователю; после этого к ней прилагается redirect_to
+// с ошибкой для приложения в формате RFC 6749.
+func (h *OAuthHandler) validateAuthorizeRequest(req *AuthorizeRequest) (*authorization, *apierr.Problem) {
+	client, err := h.oauth.GetOAuthClientByClientID(req.ClientID)
+	if err != nil {
+		return nil, apierr.New(http.StatusBadRequest, apierr.CodeOAuthClientNotFound)
+	}
+
+	// redirect_uri можно не передавать, только если у клиента он единственный
+	registered := strings.Fields(client.RedirectURIs)
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(registered) == 1 {
+		redirectURI = registered[0]
+	}
+	if !containsString(registered, redirectURI) {
+		return nil, apierr.New(http.StatusBadRequest, apierr.CodeRedirectURINotRegistered)
+	}
+
+	fail := func(oauthCode, description, code string, args ...interface{}) *apierr.Problem {
+		return apierr.New(http.StatusBadRequest, code, args...).
+			With("redirect_to", redirectWithParams(redirectURI, url.Values{
+				"error":             {oauthCode},
+				"error_description": {description},
+				"state":             {req.State},
+			}))
+	}
+
+	if req.ResponseType != "code" {
+		return nil, fail("unsupported_response_type", "Only the authorization code flow is supported",
+			apierr.CodeUnsupportedResponseType)
+	}
+
+	// PKCE обязателен для всех клиентов, допускается только S256
+	if req.CodeChallengeMethod != "S256" || len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return nil, fail("invalid_request", "code_challenge with code_challenge_method=S256 is required",
+			apierr.CodePKCERequired)
+	}
+
+	allowed := strings.Fields(client.Scopes)
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+	for _, scope := range scopes {
+		if !containsString(allowed, scope) {
+			return nil, fail("invalid_scope", "Scope is not allowed for this client: "+scope,
+				apierr.CodeScopeNotAllowed, scope)
+		}
+	}
+
+	return &authorization{
+		Client:      client,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+	}, nil
+}
+
+func redirectWithParams(redirectURI string, params url.Values) string {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// isValidRedirectURI допускает https, http только для loopback (RFC 8252)
+// и собственные схемы мобильных приложений вида com.example.app
+func isValidRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return parsed.Host != ""
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	}
+	return strings.Contains(parsed.Scheme, ".")
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
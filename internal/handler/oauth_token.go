@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/util"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Эндпоинты /oauth/* вызываются приложениями, поэтому ошибки отдаются в формате RFC 6749
+
+func oauthError(c *gin.Context, status int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// authenticateOAuthClient проверяет клиента по HTTP Basic или по полям формы.
+// Публичные клиенты передают только client_id.
+func (h *OAuthHandler) authenticateOAuthClient(c *gin.Context) (*model.OAuthClient, bool) {
+	clientID, clientSecret, basic := c.Request.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	client, err := h.oauth.GetOAuthClientByClientID(clientID)
+	if err == nil {
+		if !client.Confidential && clientSecret == "" {
+			return client, true
+		}
+		if client.Confidential && subtle.ConstantTimeCompare([]byte(util.HashToken(clientSecret)), []byte(client.ClientSecretHash)) == 1 {
+			return client, true
+		}
+	}
+
+	if basic {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+	return nil, false
+}
+
+func (h *OAuthHandler) OAuthToken(c *gin.Context) {
+	client, ok := h.authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+
+	switch c.PostForm("grant_type") {
+	case "authorization_code":
+		h.exchangeAuthorizationCode(c, client)
+	case "refresh_token":
+		h.exchangeOAuthRefreshToken(c, client)
+	case "":
+		oauthError(c, http.StatusBadRequest, "invalid_request", "grant_type is required")
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "Supported grants: authorization_code, refresh_token")
+	}
+}
+
+func (h *OAuthHandler) exchangeAuthorizationCode(c *gin.Context, client *model.OAuthClient) {
+	code := c.PostForm("code")
+	verifier := c.PostForm("code_verifier")
+	if code == "" || verifier == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "code and code_verifier are required")
+		return
+	}
+
+	authCode, err := h.oauth.RedeemOAuthAuthorizationCode(util.HashToken(code))
+	if errors.Is(err, repository.ErrAuthorizationCodeUsed) {
+		// Повторное использование кода означает, что его перехватили: отзываем выданный доступ
+		h.revokeOAuthGrant(authCode.ClientID, authCode.UserID)
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "Authorization code has already been used")
+		return
+	}
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "Authorization code is invalid or expired")
+		return
+	}
+
+	if authCode.ClientID != client.ID {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "Authorization code was issued to another client")
+		return
+	}
+
+	// redirect_uri обязателен, только если приложение передавало его при авторизации (RFC 6749, 4.1.3)
+	redirectURI := c.PostForm("redirect_uri")
+	if (authCode.RedirectURIProvided || redirectURI != "") && redirectURI != authCode.RedirectURI {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+		return
+	}
+
+	if !util.VerifyCodeChallengeS256(verifier, authCode.CodeChallenge) {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "code_verifier does not match code_challenge")
+		return
+	}
+
+	if authCode.User.DisabledAt != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "User account is disabled")
+		return
+	}
+
+	h.issueOAuthTokens(c, client, &authCode.User, strings.Fields(authCode.Scopes), nil)
+}
+
+func (h *OAuthHandler) exchangeOAuthRefreshToken(c *gin.Context, client *model.OAuthClient) {
+	rawToken := c.PostForm("refresh_token")
+	if rawToken == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+		return
+	}
+
+	token, err := h.oauth.GetOAuthRefreshTokenByHash(util.HashToken(rawToken))
+	if err != nil || token.ClientID != client.ID {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "Refresh token is invalid or expired")
+		return
+	}
+
+	if token.User.DisabledAt != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "User account is disabled")
+		return
+	}
+
+	// Приложение может сузить набор областей, но не расширить его
+	granted := strings.Fields(token.Scopes)
+	scopes := strings.Fields(c.PostForm("scope"))
+	if len(scopes) == 0 {
+		scopes = granted
+	}
+	for _, scope := range scopes {
+		if !containsString(granted, scope) {
+			oauthError(c, http.StatusBadRequest, "invalid_scope", "Scope exceeds the original grant: "+scope)
+			return
+		}
+	}
+
+	h.issueOAuthTokens(c, client, &token.User, scopes, token)
+}
+
+// issueOAuthTokens выдаёт access-токен и новый refresh-токен; previous при ротации заменяется
+func (h *OAuthHandler) issueOAuthTokens(c *gin.Context, client *model.OAuthClient, user *model.User, scopes []string, previous *model.OAuthRefreshToken) {
+	scope := strings.Join(scopes, " ")
+
+	tokenID, err := util.NewTokenID()
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+
+	accessToken, err := util.GenerateOAuthAccessToken(user, client.ClientID, scope, tokenID)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+
+	// jti запоминается, чтобы отозвать токен вместе со всем доступом приложения
+	err = h.oauth.CreateOAuthAccessToken(&model.OAuthAccessToken{
+		TokenID:   tokenID,
+		ClientID:  client.ID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(util.AccessTokenTTL),
+	})
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+
+	refreshToken, err := util.GenerateRandomString(32)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+
+	// Ограничение областей при обновлении сохраняется и в новом refresh-токене
+	stored := &model.OAuthRefreshToken{
+		TokenHash: util.HashToken(refreshToken),
+		ClientID:  client.ID,
+		UserID:    user.ID,
+		Scopes:    scope,
+		ExpiresAt: time.Now().Add(util.RefreshTokenTTL),
+	}
+	if previous == nil {
+		err = h.oauth.CreateOAuthRefreshToken(stored)
+	} else {
+		err = h.oauth.RotateOAuthRefreshToken(previous.ID, stored)
+	}
+	if errors.Is(err, repository.ErrRefreshTokenRotated) {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "Refresh token is invalid or expired")
+		return
+	}
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(util.AccessTokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+		"scope":         scope,
+	})
+}
+
+// revokeOAuthGrant отзывает выданные приложению access-токены пользователя и удаляет refresh-токены
+func (h *OAuthHandler) revokeOAuthGrant(clientID, userID int64) {
+	tokens, err := h.oauth.GetOAuthAccessTokensByGrant(clientID, userID)
+	if err != nil {
+		log.Printf("Failed to list OAuth access tokens of user %d: %v", userID, err)
+	}
+	for _, token := range tokens {
+		if err := h.revoker.Revoke(token.TokenID, token.ExpiresAt); err != nil {
+			log.Printf("Failed to revoke OAuth access token of user %d: %v", userID, err)
+		}
+	}
+
+	if err := h.oauth.DeleteOAuthRefreshTokensByGrant(clientID, userID); err != nil {
+		log.Printf("Failed to revoke OAuth grant of user %d: %v", userID, err)
+	}
+}
+
+// OAuthIntrospect — RFC 7662. Клиент видит только собственные токены, о чужих сообщается active=false.
+func (h *OAuthHandler) OAuthIntrospect(c *gin.Context) {
+	client, ok := h.authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+
+	rawToken := c.PostForm("token")
+	if rawToken == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	if claims := h.validClientAccessToken(rawToken, client); claims != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"active":     true,
+			"token_type": "Bearer",
+			"scope":      claims.Scope,
+			"client_id":  claims.ClientID,
+			"username":   claims.Username,
+			"sub":        claims.Username,
+			"jti":        claims.ID,
+			"exp":        claims.ExpiresAt.Unix(),
+			"iat":        claims.IssuedAt.Unix(),
+		})
+		return
+	}
+
+	if token, err := h.oauth.GetOAuthRefreshTokenByHash(util.HashToken(rawToken)); err == nil && token.ClientID == client.ID {
+		c.JSON(http.StatusOK, gin.H{
+			"active":     true,
+			"token_type": "refresh_token",
+			"scope":      token.Scopes,
+			"client_id":  client.ClientID,
+			"username":   token.User.Username,
+			"sub":        token.User.Username,
+			"exp":        token.ExpiresAt.Unix(),
+			"iat":        token.CreatedAt.Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"active": false,
+	})
+}
+
+// OAuthRevoke — RFC 7009. Ответ всегда 200, даже если токен неизвестен.
+func (h *OAuthHandler) OAuthRevoke(c *gin.Context) {
+	client, ok := h.authenticateOAuthClient(c)
+	if !ok {
+		return
+	}
+
+	rawToken := c.PostForm("token")
+	if rawToken == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	if claims := h.validClientAccessToken(rawToken, client); claims != nil {
+		if err := h.revoker.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+			oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token")
+			return
+		}
+	} else if token, err := h.oauth.GetOAuthRefreshTokenByHash(util.HashToken(rawToken)); err == nil && token.ClientID == client.ID {
+		if err := h.oauth.DeleteOAuthRefreshToken(token.ID); err != nil {
+			oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token")
+			return
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// validClientAccessToken возвращает claims действующего access-токена, выданного этому клиенту
+func (h *OAuthHandler) validClientAccessToken(rawToken string, client *model.OAuthClient) *util.Claims {
+	claims, err := util.ValidateToken(rawToken)
+	if err != nil || claims.ClientID != client.ClientID {
+		return nil
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := h.revoker.IsTokenRevoked(claims.ID, claims.Username, issuedAt)
+	if err != nil || revoked {
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/model"
+	"microblog/internal/oidc"
+	"microblog/internal/util"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	oidcLoginTTL    = 10 * time.Minute
+	oidcLoginCookie = "oidc_login"
+	oidcCookiePath  = "/api/auth/oidc"
+	maxUsernameLen  = 50
+)
+
+var (
+	errOIDCNoEmail         = errors.New("identity provider did not return an email")
+	errOIDCAccountConflict = errors.New("account with this email cannot be linked")
+
+	usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+)
+
+// oidcLoginState хранится в подписанной cookie между редиректом к провайдеру и callback
+type oidcLoginState struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	DeviceName   string `json:"device_name"`
+}
+
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	provider, err := h.providers.Get(c.Param("provider"))
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeUnknownProvider)
+		return
+	}
+
+	state, err := newOIDCLoginState(provider.Name(), c.Query("device_name"))
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	stateToken, err := util.GenerateActionToken(util.PurposeOIDCLogin, "", "", string(data), oidcLoginTTL)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state.State, state.Nonce, util.CodeChallengeS256(state.CodeVerifier))
+	if err != nil {
+		log.Printf("OIDC provider %s is unavailable: %v", provider.Name(), err)
+		apierr.Respond(c, http.StatusBadGateway, apierr.CodeProviderUnavailable)
+		return
+	}
+
+	setOIDCLoginCookie(c, stateToken, int(oidcLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	provider, err := h.providers.Get(c.Param("provider"))
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeUnknownProvider)
+		return
+	}
+
+	stateToken, err := c.Cookie(oidcLoginCookie)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeLoginStateInvalid)
+		return
+	}
+	// Состояние одноразовое
+	setOIDCLoginCookie(c, "", -1)
+
+	if c.Query("error") != "" {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeProviderDenied)
+		return
+	}
+
+	claims, err := util.ValidateActionToken(stateToken, util.PurposeOIDCLogin)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeLoginStateInvalid)
+		return
+	}
+
+	var state oidcLoginState
+	if err := json.Unmarshal([]byte(claims.Data), &state); err != nil ||
+		state.Provider != provider.Name() || state.State == "" || state.State != c.Query("state") {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeLoginStateInvalid)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		apierr.Fields(c, apierr.Invalid("code", "required"))
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", provider.Name(), err)
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeProviderError)
+		return
+	}
+
+	user, err := h.resolveOIDCUser(provider.Name(), identity)
+	if err != nil {
+		switch {
+		case errors.Is(err, errOIDCNoEmail):
+			apierr.Respond(c, http.StatusBadRequest, apierr.CodeProviderNoEmail)
+		case errors.Is(err, errOIDCAccountConflict):
+			apierr.Respond(c, http.StatusConflict, apierr.CodeAccountConflict)
+		default:
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		}
+		return
+	}
+
+	h.beginLogin(c, user, state.DeviceName)
+}
+
+func newOIDCLoginState(provider, deviceName string) (*oidcLoginState, error) {
+	state, err := util.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := util.GenerateRandomString(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := util.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deviceName) > 100 {
+		deviceName = deviceName[:100]
+	}
+
+	return &oidcLoginState{
+		Provider:     provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		DeviceName:   deviceName,
+	}, nil
+}
+
+func setOIDCLoginCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	// Lax: cookie должна прийти с редиректом от провайдера
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcLoginCookie, value, maxAge, oidcCookiePath, "", secure, true)
+}
+
+// resolveOIDCUser находит пользователя по привязке, привязывает существующий аккаунт
+// с тем же подтверждённым адресом или создаёт новый
+func (h *AuthHandler) resolveOIDCUser(provider string, claims *oidc.Claims) (*model.User, error) {
+	now := time.Now()
+
+	if identity, _ := h.identities.GetUserIdentity(provider, claims.Subject); identity != nil {
+		if err := h.identities.TouchUserIdentity(identity.ID, claims.Email, now); err != nil {
+			log.Printf("Failed to update identity %d: %v", identity.ID, err)
+		}
+		return &identity.User, nil
+	}
+
+	if claims.Email == "" {
+		return nil, errOIDCNoEmail
+	}
+
+	identity := &model.UserIdentity{
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: now,
+	}
+
+	if user, _ := h.users.GetUserByEmail(claims.Email); user != nil {
+		// Иначе чужой аккаунт можно было бы захватить, зарегистрировав его адрес у провайдера
+		if !claims.EmailVerified || !user.EmailVerified {
+			return nil, errOIDCAccountConflict
+		}
+		identity.UserID = user.ID
+		if err := h.identities.CreateUserIdentity(identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	username, err := h.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пароль неизвестен никому; при желании его можно задать через сброс пароля
+	randomPassword, err := util.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:      username,
+		Email:         claims.Email,
+		Password:      string(hashedPassword),
+		EmailVerified: claims.EmailVerified,
+	}
+	if err := h.identities.CreateUserWithIdentity(user, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername подбирает свободное имя на основе данных провайдера
+func (h *AuthHandler) availableUsername(claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(base, "_"), "_-")
+	if len(base) > maxUsernameLen {
+		base = base[:maxUsernameLen]
+	}
+	if len(base) < 3 || strings.EqualFold(base, model.DeletedUsername) {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		if user, _ := h.users.GetUserByUsername(candidate); user == nil {
+			return candidate, nil
+		}
+		suffix, err := util.GenerateRandomString(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + suffix
+	}
+	return "", errors.New("failed to find a free username")
+}
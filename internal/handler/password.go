@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/mailer"
+	"microblog/internal/model"
+	"microblog/internal/service"
+	"net/http"
+	"net/url"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	// Письмо отправляется в фоне, чтобы время ответа не выдавало, существует ли адрес;
+	// при остановке сервер дожидается отправки
+	if !h.worker.Go(func() { h.sendPasswordResetEmail(req.Email) }) {
+		apierr.Respond(c, http.StatusTooManyRequests, apierr.CodeRateLimited)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) sendPasswordResetEmail(email string) {
+	user, token, err := h.accounts.RequestPasswordReset(email)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to create password reset token: %v", err)
+		return
+	}
+
+	link := h.mail.Link("/reset-password", url.Values{"token": {token}})
+	err = h.mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. "+
+			"To choose a new password, open the link below:\n\n%s\n\n"+
+			"The link is valid for 1 hour and can be used once. If you did not request a reset, ignore this message.\n",
+			user.Username, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	user, err := h.accounts.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventPasswordReset, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
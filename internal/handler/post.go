@@ -2,12 +2,23 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
-	"microblog/internal/model"
-	"microblog/internal/repository"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/service"
 	"net/http"
 	"strconv"
 )
 
+// PostHandler обслуживает ленту и посты
+type PostHandler struct {
+	posts *service.PostService
+	users *service.UserService
+}
+
+func NewPostHandler(posts *service.PostService, users *service.UserService) *PostHandler {
+	return &PostHandler{posts: posts, users: users}
+}
+
 type CreatePostRequest struct {
 	Title   string `json:"title" binding:"required,min=1,max=255"`
 	Content string `json:"content" binding:"required,min=1"`
@@ -18,79 +29,57 @@ type UpdatePostRequest struct {
 	Content string `json:"content" binding:"required,min=1"`
 }
 
-func CreatePost(c *gin.Context) {
+func (h *PostHandler) CreatePost(c *gin.Context) {
 	var req CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	post := &model.Post{
-		Title:    req.Title,
-		Content:  req.Content,
-		AuthorID: user.ID,
-	}
-
-	createdPost, err := repository.CreatePost(post)
+	createdPost, err := h.posts.Create(user, req.Title, req.Content)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create post",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	createdPost.Author.Password = ""
-
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Post created successfully",
-		"post":    createdPost,
+		"post":    dto.NewPost(createdPost),
 	})
 }
 
-func GetPost(c *gin.Context) {
+func (h *PostHandler) GetPost(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
-	post, err := repository.GetPostByID(id)
+	post, err := h.posts.Get(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	post.Author.Password = ""
-
 	c.JSON(http.StatusOK, gin.H{
-		"post": post,
+		"post": dto.NewPost(post),
 	})
 }
 
-func GetAllPosts(c *gin.Context) {
+func (h *PostHandler) GetAllPosts(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "10")
 	offsetStr := c.DefaultQuery("offset", "0")
 
@@ -104,37 +93,27 @@ func GetAllPosts(c *gin.Context) {
 		offset = 0
 	}
 
-	posts, err := repository.GetAllPosts(limit, offset)
+	posts, err := h.posts.List(limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch posts",
-		})
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	for i := range posts {
-		posts[i].Author.Password = ""
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": dto.NewPosts(posts),
 	})
 }
 
-func GetMyPosts(c *gin.Context) {
+func (h *PostHandler) GetMyPosts(c *gin.Context) {
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
@@ -151,138 +130,77 @@ func GetMyPosts(c *gin.Context) {
 		offset = 0
 	}
 
-	posts, err := repository.GetPostsByAuthor(user.ID, limit, offset)
+	posts, err := h.posts.ListByAuthor(user.ID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to fetch posts",
-		})
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
 		return
 	}
 
-	for i := range posts {
-		posts[i].Author.Password = ""
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": dto.NewPosts(posts),
 	})
 }
 
-func UpdatePost(c *gin.Context) {
+func (h *PostHandler) UpdatePost(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
 	var req UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request data",
-		})
+		apierr.Validation(c, err)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	post, err := repository.GetPostByID(id)
+	result, err := h.posts.Update(actingAs(c, user), id, req.Title, req.Content)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
-		return
-	}
-
-	if post.AuthorID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You can only update your own posts",
-		})
+		respondServiceError(c, err)
 		return
 	}
 
-	updatedPost := &model.Post{
-		Title:   req.Title,
-		Content: req.Content,
-	}
-
-	result, err := repository.UpdatePost(id, updatedPost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update post",
-		})
-		return
-	}
-
-	result.Author.Password = ""
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Post updated successfully",
-		"post":    result,
+		"post":    dto.NewPost(result),
 	})
 }
 
-func DeletePost(c *gin.Context) {
+func (h *PostHandler) DeletePost(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid post ID",
-		})
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
 		return
 	}
 
 	username, exists := c.Get("username")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Unauthorized",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
 		return
 	}
 
-	user, err := repository.GetUserByUsername(username.(string))
+	user, err := h.users.GetByUsername(username.(string))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User not found",
-		})
-		return
-	}
-
-	post, err := repository.GetPostByID(id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Post not found",
-		})
-		return
-	}
-
-	if post.AuthorID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You can only delete your own posts",
-		})
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
 		return
 	}
 
-	if err := repository.DeletePost(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete post",
-		})
+	if err := h.posts.Delete(actingAs(c, user), id); err != nil {
+		respondServiceError(c, err)
 		return
 	}
 
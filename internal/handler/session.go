@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/model"
+	"microblog/internal/service"
+	"net/http"
+	"strconv"
+)
+
+const maxUserAgentLength = 500
+
+// device описывает устройство, с которого пришёл запрос
+func device(c *gin.Context, name string) service.Device {
+	return service.Device{Name: name, UserAgent: userAgent(c), IP: c.ClientIP()}
+}
+
+func userAgent(c *gin.Context) string {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
+
+// recordSecurityEvent сохраняет событие безопасности; ошибка записи не должна ломать запрос
+func (h *AuthHandler) recordSecurityEvent(c *gin.Context, userID int64, eventType, details string) {
+	err := h.securityEvents.CreateSecurityEvent(&model.SecurityEvent{
+		UserID:    userID,
+		Type:      eventType,
+		IP:        c.ClientIP(),
+		UserAgent: userAgent(c),
+		Details:   details,
+	})
+	if err != nil {
+		log.Printf("Failed to record security event %s for user %d: %v", eventType, userID, err)
+	}
+}
+
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	sessions, err := h.sessions.List(user.ID)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": dto.NewSessions(sessions, c.GetInt64("session_id")),
+	})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if err := h.sessions.Revoke(user.ID, id); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if err := h.sessions.EndOthers(user.ID, sessionID.(int64)); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+	})
+}
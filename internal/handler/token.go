@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/model"
+	"microblog/internal/util"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CreateTokenRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read posts:write comments:write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+func (h *AuthHandler) CreatePersonalAccessToken(c *gin.Context) {
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	rawToken, prefix, err := util.GeneratePersonalAccessToken()
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	token := &model.PersonalAccessToken{
+		UserID:      user.ID,
+		Name:        req.Name,
+		TokenHash:   util.HashToken(rawToken),
+		TokenPrefix: prefix,
+		Scopes:      strings.Join(req.Scopes, " "),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	createdToken, err := h.tokens.CreatePersonalAccessToken(token)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	// Сам токен показывается только один раз, в базе хранится лишь его хеш
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Token created successfully",
+		"token":        rawToken,
+		"access_token": dto.NewPersonalAccessToken(createdToken),
+	})
+}
+
+func (h *AuthHandler) GetPersonalAccessTokens(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	tokens, err := h.tokens.GetPersonalAccessTokensByUserID(user.ID)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": dto.NewPersonalAccessTokens(tokens),
+	})
+}
+
+func (h *AuthHandler) DeletePersonalAccessToken(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidID)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	token, err := h.tokens.GetPersonalAccessTokenByID(id)
+	if err != nil {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeAccessTokenNotFound)
+		return
+	}
+
+	if token.UserID != user.ID {
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeNotOwner)
+		return
+	}
+
+	if err := h.tokens.DeletePersonalAccessToken(id); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Token revoked successfully",
+	})
+}
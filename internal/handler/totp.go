@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"microblog/internal/apierr"
+	"microblog/internal/model"
+	"microblog/internal/util"
+	"net/http"
+	"time"
+)
+
+const (
+	totpIssuer        = "microblog"
+	recoveryCodeCount = 10
+	mfaChallengeTTL   = 5 * time.Minute
+)
+
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTOTPRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// verifySecondFactor принимает либо TOTP-код, либо одноразовый код восстановления
+func (h *AuthHandler) verifySecondFactor(c *gin.Context, user *model.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := util.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return false, nil
+		}
+		return h.totp.AdvanceUserTOTPStep(user.ID, step)
+	}
+
+	if recoveryCode != "" {
+		used, err := h.totp.UseRecoveryCode(user.ID, util.HashRecoveryCode(recoveryCode))
+		if err != nil || !used {
+			return false, err
+		}
+		h.recordSecurityEvent(c, user.ID, model.SecurityEventRecoveryCodeUsed, "")
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := util.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code
+		hashes[i] = util.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	claims, err := util.ValidateActionToken(req.MFAToken, util.PurposeMFAChallenge)
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	// Токен проверки одноразовый: после успешного входа он отзывается
+	revoked, err := h.revoker.IsTokenRevoked(claims.ID, claims.Subject, claims.IssuedAt.Time)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+	if revoked {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(claims.Subject)
+	if err != nil || !user.TOTPEnabled {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidOrExpiredToken)
+		return
+	}
+
+	// Коды подбираются так же, как пароли, поэтому действуют те же ограничения
+	if err := h.limiter.Check(user.Email, c.ClientIP()); err != nil {
+		respondLoginBlocked(c, err)
+		return
+	}
+
+	ok, err := h.verifySecondFactor(c, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+	if !ok {
+		h.registerLoginFailure(c, user, user.Email)
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeInvalidMFACode)
+		return
+	}
+
+	// Аккаунт могли отключить, пока пользователь вводил код
+	if user.DisabledAt != nil {
+		apierr.Respond(c, http.StatusForbidden, apierr.CodeAccountDisabled)
+		return
+	}
+
+	if err := h.revoker.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.completeLogin(c, user, claims.Data)
+}
+
+func (h *AuthHandler) SetupTOTP(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if user.TOTPEnabled {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeMFAAlreadyEnabled)
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	if err := h.totp.SetUserTOTPSecret(user.ID, secret); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": util.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if user.TOTPEnabled {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeMFAAlreadyEnabled)
+		return
+	}
+
+	if user.TOTPSecret == "" {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeMFASetupNotStarted)
+		return
+	}
+
+	step, ok := util.ValidateTOTP(user.TOTPSecret, req.Code, time.Now(), 0)
+	if !ok {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidMFACode)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	if err := h.totp.EnableUserTOTP(user.ID, step, hashes); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventTOTPEnabled, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	var req DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if !user.TOTPEnabled {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeMFANotEnabled)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeIncorrectPassword)
+		return
+	}
+
+	ok, err := h.verifySecondFactor(c, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+	if !ok {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidMFACode)
+		return
+	}
+
+	if err := h.totp.DisableUserTOTP(user.ID); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	h.recordSecurityEvent(c, user.ID, model.SecurityEventTOTPDisabled, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	if !user.TOTPEnabled {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeMFANotEnabled)
+		return
+	}
+
+	ok, err := h.verifySecondFactor(c, user, req.Code, "")
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+	if !ok {
+		apierr.Respond(c, http.StatusBadRequest, apierr.CodeInvalidMFACode)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	if err := h.totp.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+func (h *AuthHandler) GetTOTPStatus(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetUserByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	var remaining int64
+	if user.TOTPEnabled {
+		remaining, err = h.totp.CountUnusedRecoveryCodes(user.ID)
+		if err != nil {
+			apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TOTPEnabled,
+		"recovery_codes_remaining": remaining,
+	})
+}
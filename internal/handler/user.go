@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"microblog/internal/dto"
+	"microblog/internal/i18n"
+	"microblog/internal/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// UserHandler обслуживает профили пользователей
+type UserHandler struct {
+	users *service.UserService
+	posts *service.PostService
+}
+
+func NewUserHandler(users *service.UserService, posts *service.PostService) *UserHandler {
+	return &UserHandler{users: users, posts: posts}
+}
+
+// UpdateProfileRequest — частичное обновление: не переданные поля не меняются, пустая строка очищает поле
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=500"`
+	Website     *string `json:"website" binding:"omitempty,max=255"`
+	// Язык ответов API; пустая строка — по заголовку Accept-Language
+	Locale *string `json:"locale" binding:"omitempty,max=10"`
+}
+
+func (h *UserHandler) GetUserProfile(c *gin.Context) {
+	user, postsCount, err := h.users.Profile(c.Param("username"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": dto.NewUserProfile(user, postsCount),
+	})
+}
+
+func (h *UserHandler) GetUserPosts(c *gin.Context) {
+	user, err := h.users.GetByUsername(c.Param("username"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	posts, err := h.posts.ListByAuthor(user.ID, limit, offset)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": dto.NewPosts(posts),
+	})
+}
+
+func (h *UserHandler) GetMe(c *gin.Context) {
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": dto.NewUser(user),
+	})
+}
+
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.Validation(c, err)
+		return
+	}
+
+	var invalid []apierr.FieldError
+	links := []struct {
+		field string
+		value *string
+	}{
+		{"avatar_url", req.AvatarURL},
+		{"website", req.Website},
+	}
+	for _, link := range links {
+		if link.value != nil && *link.value != "" && !isWebURL(*link.value) {
+			invalid = append(invalid, apierr.Invalid(link.field, "url"))
+		}
+	}
+	if req.Locale != nil && *req.Locale != "" && !i18n.IsSupported(*req.Locale) {
+		invalid = append(invalid, apierr.Invalid("locale", "locale"))
+	}
+	if len(invalid) > 0 {
+		apierr.Fields(c, invalid...)
+		return
+	}
+
+	username, exists := c.Get("username")
+	if !exists {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUnauthorized)
+		return
+	}
+
+	user, err := h.users.GetByUsername(username.(string))
+	if err != nil {
+		apierr.Respond(c, http.StatusUnauthorized, apierr.CodeUserNotFound)
+		return
+	}
+
+	fields := make(map[string]interface{})
+	if req.DisplayName != nil {
+		fields["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Bio != nil {
+		fields["bio"] = strings.TrimSpace(*req.Bio)
+	}
+	if req.AvatarURL != nil {
+		fields["avatar_url"] = *req.AvatarURL
+	}
+	if req.Website != nil {
+		fields["website"] = *req.Website
+	}
+	// Новый язык попадёт в access-токен при следующем обновлении, а до тех пор — через Accept-Language
+	if req.Locale != nil {
+		fields["locale"] = *req.Locale
+	}
+
+	updatedUser, err := h.users.UpdateProfile(user, fields)
+	if err != nil {
+		apierr.Respond(c, http.StatusInternalServerError, apierr.CodeInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"user":    dto.NewUser(updatedUser),
+	})
+}
+
+func isWebURL(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
@@ -0,0 +1,87 @@
+package i18n
+
+var en = map[string]string{
+	// Общие ошибки
+	"bad_request":       "Invalid request data",
+	"malformed_body":    "Request body is not valid JSON",
+	"empty_body":        "Request body is required",
+	"validation_failed": "Request validation failed",
+	"invalid_id":        "Invalid identifier in the request path",
+	"not_found":         "Route not found",
+	"not_owner":         "You can only change your own resources",
+	"rate_limited":      "Too many requests, try again later",
+	"internal_error":    "Something went wrong on our side, please try again later",
+
+	// Аутентификация и доступ
+	"unauthorized":             "Authentication required",
+	"token_missing":            "Authorization header is required",
+	"token_invalid":            "Invalid access token",
+	"token_revoked":            "Token has been revoked",
+	"insufficient_scope":       "Token does not have the required scope: %s",
+	"insufficient_role":        "Insufficient permissions",
+	"session_required":         "This action requires a signed-in session",
+	"invalid_credentials":      "Invalid credentials",
+	"account_locked":           "Account is temporarily locked due to too many failed login attempts",
+	"account_disabled":         "Account has been disabled by an administrator",
+	"too_many_attempts":        "Too many failed login attempts, try again later",
+	"refresh_token_reuse":      "Refresh token reuse detected, session revoked",
+	"invalid_or_expired_token": "The token or link is invalid or has expired",
+
+	// Пользователи и аккаунт
+	"user_not_found":                   "User not found",
+	"username_taken":                   "Username already taken",
+	"email_taken":                      "Email already registered",
+	"email_unchanged":                  "New email must differ from the current one",
+	"email_not_verified":               "Email verification required",
+	"email_already_verified":           "Email already verified",
+	"incorrect_password":               "Password is incorrect",
+	"own_role_change":                  "You cannot change your own role",
+	"deletion_already_scheduled":       "Account deletion is already scheduled",
+	"deletion_not_scheduled":           "Account deletion is not scheduled",
+	"export_in_progress":               "Data export is already in progress",
+	"export_not_found":                 "Data export not found or expired",
+	"session_not_found":                "Session not found",
+	"access_token_not_found":           "Token not found",
+	"oauth_client_not_found":           "Client not found",
+	"invalid_mfa_code":                 "Invalid authentication code",
+	"mfa_already_enabled":              "Two-factor authentication is already enabled",
+	"mfa_not_enabled":                  "Two-factor authentication is not enabled",
+	"mfa_setup_not_started":            "Two-factor setup was not started",
+	"verification_email_recently_sent": "Verification email was sent recently, try again later",
+
+	// Контент
+	"post_not_found":    "Post not found",
+	"comment_not_found": "Comment not found",
+
+	// Вход через внешних провайдеров
+	"unknown_provider":     "Unknown identity provider",
+	"login_state_invalid":  "Login session expired, please start again",
+	"provider_denied":      "Login was cancelled or denied by the identity provider",
+	"provider_error":       "Failed to verify identity provider response",
+	"provider_no_email":    "Identity provider did not share an email address",
+	"provider_unavailable": "Identity provider is unavailable",
+	"account_conflict":     "An account with this email already exists; sign in with your password to continue",
+
+	// Запрос авторизации стороннего приложения
+	"redirect_uri_not_registered": "redirect_uri is not registered for this client",
+	"unsupported_response_type":   "Only the authorization code flow is supported",
+	"pkce_required":               "code_challenge with code_challenge_method=S256 is required",
+	"scope_not_allowed":           "Scope is not allowed for this client: %s",
+
+	// Ошибки полей: %[1]s — имя поля, %[2]s — параметр правила
+	"validation.required":     "%[1]s is required",
+	"validation.email":        "%[1]s must be a valid email address",
+	"validation.oneof":        "%[1]s must be one of: %[2]s",
+	"validation.min.string":   "%[1]s must be at least %[2]s characters long",
+	"validation.min.items":    "%[1]s must contain at least %[2]s items",
+	"validation.min.number":   "%[1]s must be at least %[2]s",
+	"validation.max.string":   "%[1]s must be at most %[2]s characters long",
+	"validation.max.items":    "%[1]s must contain at most %[2]s items",
+	"validation.max.number":   "%[1]s must be at most %[2]s",
+	"validation.type":         "%[1]s must be of type %[2]s",
+	"validation.username":     "%[1]s must contain only Latin letters, numbers, underscores, and hyphens",
+	"validation.url":          "%[1]s must be an absolute http or https URL",
+	"validation.redirect_uri": "%[1]s must be an absolute https URL, a loopback http URL or a private-use scheme",
+	"validation.locale":       "%[1]s must be one of the supported languages: en, ru",
+	"validation.invalid":      "%[1]s is invalid",
+}
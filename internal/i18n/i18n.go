@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки ответов API
+const (
+	English = "en"
+	Russian = "ru"
+
+	Default = English
+)
+
+// ContextKey — ключ gin.Context, под которым лежит язык текущего запроса
+const ContextKey = "locale"
+
+var catalogs = map[string]map[string]string{
+	English: en,
+	Russian: ru,
+}
+
+func Supported() []string {
+	return []string{English, Russian}
+}
+
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// T возвращает сообщение по ключу (обычно это код ошибки) на нужном языке.
+// Если перевода нет, используется английский текст, а если нет и его — сам ключ.
+func T(locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// FromContext возвращает язык запроса: выбранный middleware или, если его не было, из Accept-Language
+func FromContext(c *gin.Context) string {
+	if locale := c.GetString(ContextKey); locale != "" {
+		return locale
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// Negotiate выбирает язык из заголовка Accept-Language (RFC 9110) среди поддерживаемых
+func Negotiate(header string) string {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, languageRange{tag: tag, quality: quality})
+		}
+	}
+
+	// При равном весе важен порядок в заголовке
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return Default
+		}
+		// ru-RU, en-GB и т.п. сводятся к основному языку
+		primary, _, _ := strings.Cut(r.tag, "-")
+		if IsSupported(primary) {
+			return primary
+		}
+	}
+	return Default
+}
@@ -0,0 +1,87 @@
+package i18n
+
+var ru = map[string]string{
+	// Общие ошибки
+	"bad_request":       "Некорректные данные запроса",
+	"malformed_body":    "Тело запроса не является корректным JSON",
+	"empty_body":        "Тело запроса не передано",
+	"validation_failed": "Данные запроса не прошли проверку",
+	"invalid_id":        "Некорректный идентификатор в адресе запроса",
+	"not_found":         "Маршрут не найден",
+	"not_owner":         "Изменять можно только свои ресурсы",
+	"rate_limited":      "Слишком много запросов, попробуйте позже",
+	"internal_error":    "Что-то пошло не так на нашей стороне, попробуйте позже",
+
+	// Аутентификация и доступ
+	"unauthorized":             "Требуется аутентификация",
+	"token_missing":            "Не передан заголовок Authorization",
+	"token_invalid":            "Недействительный токен доступа",
+	"token_revoked":            "Токен отозван",
+	"insufficient_scope":       "У токена нет нужной области доступа: %s",
+	"insufficient_role":        "Недостаточно прав",
+	"session_required":         "Это действие доступно только в сессии пользователя",
+	"invalid_credentials":      "Неверный email или пароль",
+	"account_locked":           "Аккаунт временно заблокирован из-за слишком большого числа неудачных попыток входа",
+	"account_disabled":         "Аккаунт отключён администратором",
+	"too_many_attempts":        "Слишком много неудачных попыток входа, попробуйте позже",
+	"refresh_token_reuse":      "Обнаружено повторное использование refresh-токена, сессия завершена",
+	"invalid_or_expired_token": "Токен или ссылка недействительны либо истекли",
+
+	// Пользователи и аккаунт
+	"user_not_found":                   "Пользователь не найден",
+	"username_taken":                   "Имя пользователя уже занято",
+	"email_taken":                      "Этот email уже зарегистрирован",
+	"email_unchanged":                  "Новый email должен отличаться от текущего",
+	"email_not_verified":               "Необходимо подтвердить email",
+	"email_already_verified":           "Email уже подтверждён",
+	"incorrect_password":               "Неверный пароль",
+	"own_role_change":                  "Нельзя изменить собственную роль",
+	"deletion_already_scheduled":       "Удаление аккаунта уже запланировано",
+	"deletion_not_scheduled":           "Удаление аккаунта не запланировано",
+	"export_in_progress":               "Выгрузка данных уже выполняется",
+	"export_not_found":                 "Выгрузка данных не найдена или устарела",
+	"session_not_found":                "Сессия не найдена",
+	"access_token_not_found":           "Токен не найден",
+	"oauth_client_not_found":           "Приложение не найдено",
+	"invalid_mfa_code":                 "Неверный код подтверждения",
+	"mfa_already_enabled":              "Двухфакторная аутентификация уже включена",
+	"mfa_not_enabled":                  "Двухфакторная аутентификация не включена",
+	"mfa_setup_not_started":            "Настройка двухфакторной аутентификации не начата",
+	"verification_email_recently_sent": "Письмо с подтверждением уже отправлено недавно, попробуйте позже",
+
+	// Контент
+	"post_not_found":    "Пост не найден",
+	"comment_not_found": "Комментарий не найден",
+
+	// Вход через внешних провайдеров
+	"unknown_provider":     "Неизвестный провайдер входа",
+	"login_state_invalid":  "Сеанс входа истёк, начните заново",
+	"provider_denied":      "Вход отменён или отклонён провайдером",
+	"provider_error":       "Не удалось проверить ответ провайдера входа",
+	"provider_no_email":    "Провайдер входа не передал адрес email",
+	"provider_unavailable": "Провайдер входа недоступен",
+	"account_conflict":     "Аккаунт с этим email уже существует; войдите с паролем, чтобы продолжить",
+
+	// Запрос авторизации стороннего приложения
+	"redirect_uri_not_registered": "Адрес возврата не зарегистрирован для этого приложения",
+	"unsupported_response_type":   "Поддерживается только получение кода авторизации",
+	"pkce_required":               "Требуется code_challenge с code_challenge_method=S256",
+	"scope_not_allowed":           "Область доступа не разрешена этому приложению: %s",
+
+	// Ошибки полей: %[1]s — имя поля, %[2]s — параметр правила
+	"validation.required":     "Поле %[1]s обязательно",
+	"validation.email":        "Поле %[1]s должно содержать корректный email",
+	"validation.oneof":        "Поле %[1]s должно принимать одно из значений: %[2]s",
+	"validation.min.string":   "Поле %[1]s: минимальная длина — %[2]s",
+	"validation.min.items":    "Поле %[1]s: минимальное число элементов — %[2]s",
+	"validation.min.number":   "Поле %[1]s: минимальное значение — %[2]s",
+	"validation.max.string":   "Поле %[1]s: максимальная длина — %[2]s",
+	"validation.max.items":    "Поле %[1]s: максимальное число элементов — %[2]s",
+	"validation.max.number":   "Поле %[1]s: максимальное значение — %[2]s",
+	"validation.type":         "Поле %[1]s должно иметь тип %[2]s",
+	"validation.username":     "Поле %[1]s может содержать только латинские буквы, цифры, подчёркивания и дефисы",
+	"validation.url":          "Поле %[1]s должно содержать абсолютный адрес http или https",
+	"validation.redirect_uri": "Поле %[1]s должно содержать абсолютный адрес https, адрес http на loopback или собственную схему приложения",
+	"validation.locale":       "Поле %[1]s должно содержать один из поддерживаемых языков: en, ru",
+	"validation.invalid":      "Поле %[1]s заполнено некорректно",
+}
@@ -0,0 +1,164 @@
+package lockout
+
+import (
+	"fmt"
+	"math"
+	"microblog/internal/config"
+	"microblog/internal/repository"
+	"strings"
+	"time"
+)
+
+// Attempts — состояние неудачных попыток входа по одному ключу (аккаунт или IP)
+type Attempts struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+	// Locked отличает полную блокировку от задержки между попытками
+	Locked bool
+}
+
+// BlockFunc по числу неудачных попыток решает, до какого момента блокировать ключ
+type BlockFunc func(failures int, now time.Time) (blockedUntil time.Time, locked bool)
+
+type Store interface {
+	Get(key string) (Attempts, error)
+	// RecordFailure атомарно увеличивает счётчик и сохраняет блокировку, вычисленную block.
+	// Если последняя неудача была раньше window, счёт начинается заново.
+	RecordFailure(key string, now time.Time, window time.Duration, block BlockFunc) (Attempts, error)
+	Reset(key string) error
+}
+
+// Policy описывает экспоненциальную задержку и блокировку для одного вида ключей
+type Policy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	MaxFailures     int
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+func (p Policy) block(failures int, now time.Time) (time.Time, bool) {
+	if failures >= p.MaxFailures {
+		return now.Add(p.LockoutDuration), true
+	}
+	if failures <= p.FreeAttempts {
+		return time.Time{}, false
+	}
+
+	delay := p.BaseDelay * time.Duration(math.Pow(2, float64(failures-p.FreeAttempts-1)))
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	return now.Add(delay), false
+}
+
+// BlockedError возвращается, пока ключ заблокирован
+type BlockedError struct {
+	RetryAfter time.Duration
+	// AccountLocked — аккаунт заблокирован целиком (423), иначе это ограничение частоты (429)
+	AccountLocked bool
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("login blocked, retry after %s", e.RetryAfter)
+}
+
+// Limiter ограничивает подбор пароля по аккаунту и по IP
+type Limiter struct {
+	store         Store
+	accountPolicy Policy
+	ipPolicy      Policy
+}
+
+// New выбирает хранилище попыток по LOGIN_LOCKOUT_STORE
+func New(cfg *config.Config, attempts repository.LoginAttemptRepository) (*Limiter, error) {
+	switch cfg.Lockout.Store {
+	case "memory":
+		return NewLimiter(cfg, NewMemoryStore()), nil
+	case "postgres":
+		return NewLimiter(cfg, NewPostgresStore(attempts)), nil
+	default:
+		return nil, fmt.Errorf("unknown lockout store %q", cfg.Lockout.Store)
+	}
+}
+
+func NewLimiter(cfg *config.Config, store Store) *Limiter {
+	return &Limiter{
+		store: store,
+		accountPolicy: Policy{
+			FreeAttempts:    3,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			MaxFailures:     cfg.Lockout.MaxFailures,
+			LockoutDuration: cfg.Lockout.LockoutDuration,
+			Window:          cfg.Lockout.LockoutDuration,
+		},
+		// С одного IP могут входить несколько человек, поэтому порог выше
+		ipPolicy: Policy{
+			FreeAttempts:    10,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			MaxFailures:     cfg.Lockout.IPMaxFailures,
+			LockoutDuration: cfg.Lockout.LockoutDuration,
+			Window:          cfg.Lockout.LockoutDuration,
+		},
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check возвращает *BlockedError, если вход для аккаунта или IP сейчас запрещён
+func (l *Limiter) Check(email, ip string) error {
+	now := time.Now()
+
+	account, err := l.store.Get(accountKey(email))
+	if err != nil {
+		return err
+	}
+	if now.Before(account.BlockedUntil) {
+		return &BlockedError{RetryAfter: account.BlockedUntil.Sub(now), AccountLocked: account.Locked}
+	}
+
+	byIP, err := l.store.Get(ipKey(ip))
+	if err != nil {
+		return err
+	}
+	if now.Before(byIP.BlockedUntil) {
+		return &BlockedError{RetryAfter: byIP.BlockedUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+// RecordFailure учитывает неудачную попытку; возвращает true, если аккаунт только что заблокирован
+func (l *Limiter) RecordFailure(email, ip string) (bool, error) {
+	now := time.Now()
+
+	account, err := l.store.RecordFailure(accountKey(email), now, l.accountPolicy.Window, l.accountPolicy.block)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := l.store.RecordFailure(ipKey(ip), now, l.ipPolicy.Window, l.ipPolicy.block); err != nil {
+		return false, err
+	}
+
+	return account.Locked && account.Failures == l.accountPolicy.MaxFailures, nil
+}
+
+func (l *Limiter) RecordSuccess(email string) error {
+	return l.store.Reset(accountKey(email))
+}
+
+// Unlock снимает блокировку аккаунта вручную
+func (l *Limiter) Unlock(email string) error {
+	return l.store.Reset(accountKey(email))
+}
@@ -0,0 +1,58 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore держит счётчики в памяти процесса; подходит только для одного экземпляра сервиса
+type MemoryStore struct {
+	mu        sync.Mutex
+	attempts  map[string]Attempts
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempts)}
+}
+
+func (s *MemoryStore) Get(key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) RecordFailure(key string, now time.Time, window time.Duration, block BlockFunc) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Давно забытые ключи убираем не чаще раза за window, чтобы карта не росла бесконечно,
+	// а каждая попытка во время перебора не проходила по ней целиком
+	if now.Sub(s.lastSweep) > window {
+		for k, a := range s.attempts {
+			if now.Sub(a.LastFailure) > window && now.After(a.BlockedUntil) {
+				delete(s.attempts, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	a := s.attempts[key]
+	if now.Sub(a.LastFailure) > window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = now
+	a.BlockedUntil, a.Locked = block(a.Failures, now)
+	s.attempts[key] = a
+	return a, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,75 @@
+package lockout
+
+import (
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"sync"
+	"time"
+)
+
+// PostgresStore хранит счётчики в базе, общей для всех экземпляров сервиса
+type PostgresStore struct {
+	attempts repository.LoginAttemptRepository
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(attempts repository.LoginAttemptRepository) *PostgresStore {
+	return &PostgresStore{attempts: attempts}
+}
+
+func (s *PostgresStore) Get(key string) (Attempts, error) {
+	attempt, err := s.attempts.GetLoginAttempt(key)
+	if err != nil {
+		return Attempts{}, err
+	}
+	return toAttempts(attempt), nil
+}
+
+func (s *PostgresStore) RecordFailure(key string, now time.Time, window time.Duration, block BlockFunc) (Attempts, error) {
+	attempt, err := s.attempts.UpdateLoginAttempt(key, func(attempt *model.LoginAttempt) {
+		if now.Sub(attempt.LastFailure) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailure = now
+		attempt.BlockedUntil, attempt.Locked = block(attempt.Failures, now)
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+
+	if err := s.sweep(now, window); err != nil {
+		return Attempts{}, err
+	}
+
+	return toAttempts(attempt), nil
+}
+
+// sweep удаляет старые счётчики, которые больше ни на что не влияют. Запрос идёт по всей таблице,
+// поэтому каждый экземпляр запускает его не чаще раза за window, а не на каждую неудачную попытку.
+func (s *PostgresStore) sweep(now time.Time, window time.Duration) error {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) <= window {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	return s.attempts.DeleteStaleLoginAttempts(now.Add(-window))
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return s.attempts.DeleteLoginAttempt(key)
+}
+
+func toAttempts(attempt *model.LoginAttempt) Attempts {
+	return Attempts{
+		Failures:     attempt.Failures,
+		LastFailure:  attempt.LastFailure,
+		BlockedUntil: attempt.BlockedUntil,
+		Locked:       attempt.Locked,
+	}
+}
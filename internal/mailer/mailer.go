@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"fmt"
+	"microblog/internal/config"
+	"net/url"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Sender отправляет письма и строит ссылки на публичный адрес сервиса
+type Sender struct {
+	mailer    Mailer
+	publicURL string
+}
+
+// NewTransport выбирает способ доставки по MAIL_DRIVER
+func NewTransport(cfg *config.Config) (Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.Mail), nil
+	case "file":
+		return NewFileMailer(cfg.Mail.FileDir, cfg.Mail.From), nil
+	case "log":
+		return NewFileMailer("", cfg.Mail.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+	}
+}
+
+func NewSender(m Mailer, publicURL string) *Sender {
+	return &Sender{mailer: m, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (s *Sender) Send(msg Message) error {
+	return s.mailer.Send(msg)
+}
+
+// Link строит абсолютную ссылку для письма
+func (s *Sender) Link(path string, params url.Values) string {
+	link := s.publicURL + path
+	if len(params) > 0 {
+		link += "?" + params.Encode()
+	}
+	return link
+}
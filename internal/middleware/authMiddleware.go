@@ -2,35 +2,151 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"log"
+	"microblog/internal/apierr"
+	"microblog/internal/i18n"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
 	"microblog/internal/util"
 	"net/http"
 	"strings"
+	"time"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Способы аутентификации запроса
+const (
+	AuthMethodSession = "session"
+	AuthMethodToken   = "personal_access_token"
+	AuthMethodOAuth   = "oauth"
+)
+
+// Время последнего использования токена обновляется не чаще этого интервала
+const tokenTouchInterval = time.Minute
+
+// AuthMiddleware проверяет access-токен сессии или приложения либо персональный токен из tokens;
+// отозванные токены отсекает revoker
+func AuthMiddleware(tokens repository.PersonalAccessTokenRepository, revoker *revocation.Revoker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			apierr.Abort(c, http.StatusUnauthorized, apierr.CodeTokenMissing)
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-			c.Abort()
+			apierr.Abort(c, http.StatusUnauthorized, apierr.CodeTokenInvalid)
+			return
+		}
+
+		if strings.HasPrefix(bearerToken[1], util.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, tokens, bearerToken[1])
 			return
 		}
 
 		claims, err := util.ValidateToken(bearerToken[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierr.Abort(c, http.StatusUnauthorized, apierr.CodeTokenInvalid)
+			return
+		}
+
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+
+		revoked, err := revoker.IsTokenRevoked(claims.ID, claims.Username, issuedAt)
+		if err != nil {
+			apierr.Abort(c, http.StatusInternalServerError, apierr.CodeInternal)
+			return
+		}
+		if revoked {
+			apierr.Abort(c, http.StatusUnauthorized, apierr.CodeTokenRevoked)
 			return
 		}
 
 		c.Set("username", claims.Username)
+		c.Set("token_id", claims.ID)
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+		if claims.Locale != "" {
+			c.Set(i18n.ContextKey, claims.Locale)
+		}
+
+		// Токены сторонних приложений, как и персональные, ограничены областями и не дают полномочий роли
+		if claims.ClientID != "" {
+			c.Set("role", model.RoleUser)
+			c.Set("auth_method", AuthMethodOAuth)
+			c.Set("client_id", claims.ClientID)
+			c.Set("scopes", strings.Fields(claims.Scope))
+			c.Next()
+			return
+		}
+
+		c.Set("role", claims.Role)
+		c.Set("auth_method", AuthMethodSession)
+		c.Set("session_id", claims.SessionID)
+		c.Next()
+	}
+}
+
+func authenticatePersonalAccessToken(c *gin.Context, tokens repository.PersonalAccessTokenRepository, rawToken string) {
+	token, err := tokens.GetPersonalAccessTokenByHash(util.HashToken(rawToken))
+	if err != nil {
+		apierr.Abort(c, http.StatusUnauthorized, apierr.CodeTokenInvalid)
+		return
+	}
+
+	// Персональные токены переживают отключение аккаунта, но не действуют, пока он отключён
+	if token.User.DisabledAt != nil {
+		apierr.Abort(c, http.StatusForbidden, apierr.CodeAccountDisabled)
+		return
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenTouchInterval {
+		if err := tokens.TouchPersonalAccessToken(token.ID, now); err != nil {
+			log.Printf("Failed to update last use of token %d: %v", token.ID, err)
+		}
+	}
+
+	// Персональный токен не даёт полномочий роли владельца: для модерации нужен вход в сессию
+	c.Set("username", token.User.Username)
+	c.Set("role", model.RoleUser)
+	if token.User.Locale != "" {
+		c.Set(i18n.ContextKey, token.User.Locale)
+	}
+	c.Set("auth_method", AuthMethodToken)
+	c.Set("scopes", strings.Fields(token.Scopes))
+	c.Next()
+}
+
+// RequireScope ограничивает доступ токенов без нужной области; сессии пользователя проходят всегда
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == AuthMethodSession {
+			c.Next()
+			return
+		}
+
+		for _, granted := range c.GetStringSlice("scopes") {
+			if granted == scope {
+				c.Next()
+				return
+			}
+		}
+
+		apierr.Abort(c, http.StatusForbidden, apierr.CodeInsufficientScope, scope)
+	}
+}
+
+// RequireSession закрывает управление аккаунтом от токенов доступа
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != AuthMethodSession {
+			apierr.Abort(c, http.StatusForbidden, apierr.CodeSessionRequired)
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"microblog/internal/i18n"
+)
+
+// Locale выбирает язык ответа по Accept-Language. Язык из профиля пользователя
+// важнее заголовка и подставляется в AuthMiddleware.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(i18n.ContextKey, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
+	"net/http"
+)
+
+// RequireRole пропускает только пользователей с одной из ролей; ставится после AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		apierr.Abort(c, http.StatusForbidden, apierr.CodeInsufficientRole)
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+)
+
+// DataExport — запрос пользователя на архив со всеми его данными
+type DataExport struct {
+	ID          int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int64      `json:"user_id" gorm:"not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID"`
+	Status      string     `json:"status" gorm:"size:20;not null;index"`
+	FilePath    string     `json:"-" gorm:"size:500"`
+	FileSize    int64      `json:"file_size"`
+	StartedAt   *time.Time `json:"-"`
+	CompletedAt *time.Time `json:"completed_at"`
+	// После этого момента архив удаляется
+	ExpiresAt *time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// LoginAttempt — счётчик неудачных входов по ключу вида account:<email> или ip:<addr>
+type LoginAttempt struct {
+	Key          string    `gorm:"primaryKey;size:255"`
+	Failures     int       `gorm:"not null;default:0"`
+	LastFailure  time.Time `gorm:"not null;index"`
+	BlockedUntil time.Time
+	Locked       bool `gorm:"not null;default:false"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// OAuthClient — стороннее приложение, которое получает доступ к API от имени пользователей
+type OAuthClient struct {
+	ID       int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	ClientID string `json:"client_id" gorm:"size:64;not null;uniqueIndex"`
+	// Пустой хеш у публичных клиентов (SPA, мобильные приложения): они защищены только PKCE
+	ClientSecretHash string    `json:"-" gorm:"size:64"`
+	Confidential     bool      `json:"confidential" gorm:"not null;default:false"`
+	Name             string    `json:"name" gorm:"size:100;not null"`
+	RedirectURIs     string    `json:"redirect_uris" gorm:"type:text;not null"`
+	Scopes           string    `json:"scopes" gorm:"size:255;not null"`
+	OwnerID          int64     `json:"owner_id" gorm:"not null;index"`
+	Owner            User      `json:"-" gorm:"foreignKey:OwnerID"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// OAuthAuthorizationCode — код авторизации. RedirectURI — адрес, на который выдан код: если приложение
+// не передало его, здесь единственный зарегистрированный адрес, а RedirectURIProvided — false.
+type OAuthAuthorizationCode struct {
+	ID                  int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	CodeHash            string      `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ClientID            int64       `json:"client_id" gorm:"not null;index"`
+	Client              OAuthClient `json:"-" gorm:"foreignKey:ClientID"`
+	UserID              int64       `json:"user_id" gorm:"not null;index"`
+	User                User        `json:"-" gorm:"foreignKey:UserID"`
+	RedirectURI         string      `json:"redirect_uri" gorm:"type:text;not null"`
+	RedirectURIProvided bool        `json:"redirect_uri_provided" gorm:"not null;default:false"`
+	Scopes              string      `json:"scopes" gorm:"size:255;not null"`
+	CodeChallenge       string      `json:"-" gorm:"size:128;not null"`
+	ExpiresAt           time.Time   `json:"expires_at" gorm:"not null"`
+	UsedAt              *time.Time  `json:"used_at"`
+	CreatedAt           time.Time   `json:"created_at"`
+}
+
+type OAuthRefreshToken struct {
+	ID        int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TokenHash string      `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ClientID  int64       `json:"client_id" gorm:"not null;index"`
+	Client    OAuthClient `json:"-" gorm:"foreignKey:ClientID"`
+	UserID    int64       `json:"user_id" gorm:"not null;index"`
+	User      User        `json:"-" gorm:"foreignKey:UserID"`
+	Scopes    string      `json:"scopes" gorm:"size:255;not null"`
+	ExpiresAt time.Time   `json:"expires_at" gorm:"not null"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// OAuthAccessToken — выданный приложению access-токен. Сам токен не хранится, только jti:
+// по нему доступ отзывается, если код авторизации использовали повторно.
+type OAuthAccessToken struct {
+	ID        int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TokenID   string      `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ClientID  int64       `json:"client_id" gorm:"not null;index"`
+	Client    OAuthClient `json:"-" gorm:"foreignKey:ClientID"`
+	UserID    int64       `json:"user_id" gorm:"not null;index"`
+	User      User        `json:"-" gorm:"foreignKey:UserID"`
+	ExpiresAt time.Time   `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Без явных имён GORM называет таблицы o_auth_*
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+func (OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+func (OAuthAccessToken) TableName() string {
+	return "oauth_access_tokens"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Области действия токенов доступа
+const (
+	ScopeRead          = "read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+)
+
+type PersonalAccessToken struct {
+	ID          int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int64      `json:"user_id" gorm:"not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID"`
+	Name        string     `json:"name" gorm:"size:100;not null"`
+	TokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	TokenPrefix string     `json:"token_prefix" gorm:"size:16;not null"`
+	Scopes      string     `json:"scopes" gorm:"size:255;not null"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// ErasedUserRevocation хранит границу отзыва удалённого аккаунта: строки users уже нет,
+// а выданные ему access-токены действуют до ExpiresAt и не должны достаться новому владельцу имени
+type ErasedUserRevocation struct {
+	Username   string    `gorm:"primaryKey;size:100"`
+	ValidAfter time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	SecurityEventLoginSucceeded    = "login_succeeded"
+	SecurityEventLoginFailed       = "login_failed"
+	SecurityEventAccountLocked     = "account_locked"
+	SecurityEventAccountUnlocked   = "account_unlocked"
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+	SecurityEventPasswordReset     = "password_reset"
+	SecurityEventPasswordChanged   = "password_changed"
+	SecurityEventEmailChanged      = "email_changed"
+	SecurityEventTOTPEnabled       = "totp_enabled"
+	SecurityEventTOTPDisabled      = "totp_disabled"
+	SecurityEventRecoveryCodeUsed  = "recovery_code_used"
+	SecurityEventRoleChanged       = "role_changed"
+	SecurityEventDeletionScheduled = "deletion_scheduled"
+	SecurityEventDeletionCanceled  = "deletion_canceled"
+	SecurityEventDataExported      = "data_exported"
+	SecurityEventAccountDisabled   = "account_disabled"
+	SecurityEventAccountEnabled    = "account_enabled"
+	SecurityEventTokensRevoked     = "tokens_revoked"
+)
+
+type SecurityEvent struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `json:"user_id" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"size:50;not null;index"`
+	IP        string    `json:"ip" gorm:"size:45"`
+	UserAgent string    `json:"user_agent" gorm:"size:500"`
+	Details   string    `json:"details" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID       int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID   int64  `json:"user_id" gorm:"not null;index"`
+	User     User   `json:"-" gorm:"foreignKey:UserID"`
+	FamilyID string `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Sequence int    `json:"-" gorm:"not null;default:0"`
+	// ID последнего выданного access-токена, чтобы отозвать его вместе с сессией
+	AccessTokenID string    `json:"-" gorm:"size:64"`
+	DeviceName    string    `json:"device_name" gorm:"size:100"`
+	UserAgent     string    `json:"user_agent" gorm:"size:500"`
+	IP            string    `json:"ip" gorm:"size:45"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastUsedAt    time.Time `json:"last_used_at"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"not null;index"`
+}
@@ -2,11 +2,47 @@ package model
 
 import "time"
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// DeletedUsername — служебный пользователь, которому переходит контент удалённых аккаунтов
+const DeletedUsername = "deleted"
+
 type User struct {
-	ID           int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Username     string    `json:"username" gorm:"size:100;not null;uniqueIndex"`
-	Password     string    `json:"password" gorm:"size:255;not null"`
-	Email        string    `json:"email" gorm:"size:100;not null;uniqueIndex"`
-	RefreshToken string    `json:"-" gorm:"size:500"`
-	TokenExpiry  time.Time `json:"-"`
+	ID               int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Username         string     `json:"username" gorm:"size:100;not null;uniqueIndex"`
+	Password         string     `json:"-" gorm:"size:255;not null"`
+	Email            string     `json:"email" gorm:"size:100;not null;uniqueIndex"`
+	Role             string     `json:"role" gorm:"size:20;not null;default:user"`
+	TokensValidAfter *time.Time `json:"-"`
+
+	EmailVerified           bool       `json:"email_verified" gorm:"not null;default:false"`
+	EmailVerificationSentAt *time.Time `json:"-"`
+	// Новый адрес, ожидающий подтверждения
+	PendingEmail string `json:"-" gorm:"size:100"`
+
+	// Секрет появляется при настройке 2FA, но действует только после подтверждения кодом
+	TOTPSecret   string `json:"-" gorm:"size:64"`
+	TOTPEnabled  bool   `json:"totp_enabled" gorm:"not null;default:false"`
+	TOTPLastStep int64  `json:"-" gorm:"not null;default:0"`
+
+	// Публичный профиль
+	DisplayName string `json:"display_name" gorm:"size:100"`
+	Bio         string `json:"bio" gorm:"size:500"`
+	AvatarURL   string `json:"avatar_url" gorm:"size:500"`
+	Website     string `json:"website" gorm:"size:255"`
+	// Язык ответов API; пустой — по заголовку Accept-Language
+	Locale    string    `json:"locale" gorm:"size:10"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Когда аккаунт будет окончательно удалён; до этого удаление можно отменить
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at" gorm:"index"`
+	// Отключённый администратором аккаунт не может войти, его токены не принимаются
+	DisabledAt *time.Time `json:"disabled_at"`
+	// Служебный пользователь deleted; удаление аккаунтов ищет его по этому признаку, а не по имени
+	Tombstone bool `json:"-" gorm:"not null;default:false"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// UserIdentity связывает пользователя с учётной записью у внешнего OIDC-провайдера
+type UserIdentity struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int64     `json:"user_id" gorm:"not null;index"`
+	User        User      `json:"-" gorm:"foreignKey:UserID"`
+	Provider    string    `json:"provider" gorm:"size:50;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string    `json:"-" gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string    `json:"email" gorm:"size:100"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package policy
+
+import "microblog/internal/model"
+
+// Правила доступа к чужому контенту: администраторы управляют любыми постами,
+// модераторы и администраторы могут удалять любые комментарии
+
+func CanUpdatePost(user *model.User, post *model.Post) bool {
+	return post.AuthorID == user.ID || user.Role == model.RoleAdmin
+}
+
+func CanDeletePost(user *model.User, post *model.Post) bool {
+	return post.AuthorID == user.ID || user.Role == model.RoleAdmin
+}
+
+func CanUpdateComment(user *model.User, comment *model.Comment) bool {
+	return comment.AuthorID == user.ID
+}
+
+func CanDeleteComment(user *model.User, comment *model.Comment) bool {
+	return comment.AuthorID == user.ID || user.Role == model.RoleModerator || user.Role == model.RoleAdmin
+}
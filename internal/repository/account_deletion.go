@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"microblog/internal/model"
+	"time"
+)
+
+var (
+	ErrDeletionNotDue = errors.New("account deletion is not scheduled or not due yet")
+	// Имя мог занять обычный аккаунт, зарегистрированный до запрета этого имени; его нужно переименовать
+	ErrTombstoneUsernameTaken = errors.New("username \"" + model.DeletedUsername + "\" belongs to a regular account")
+)
+
+// ScheduleUserDeletion назначает удаление аккаунта; nil отменяет его
+func (r *userRepository) ScheduleUserDeletion(userID int64, at *time.Time) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("deletion_scheduled_at", at)
+	return result.Error
+}
+
+func (r *userRepository) GetUsersDueForDeletion(now time.Time, limit, offset int) ([]model.User, error) {
+	var users []model.User
+	result := r.db.
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+// EraseUser в одной транзакции удаляет пользователя и все его персональные данные.
+// Посты и комментарии при anonymize переходят служебному пользователю deleted, иначе удаляются.
+func (r *userRepository) EraseUser(userID int64, anonymize bool, now time.Time) (*model.User, error) {
+	var user model.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Пользователь мог отменить удаление, пока задача ждала своей очереди
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", userID, now).
+			Limit(1).
+			Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeletionNotDue
+		}
+
+		if anonymize {
+			if err := reassignUserContent(tx, user.ID); err != nil {
+				return err
+			}
+		} else if err := deleteUserContent(tx, user.ID); err != nil {
+			return err
+		}
+
+		return deleteUserData(tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func reassignUserContent(tx *gorm.DB, userID int64) error {
+	var tombstone model.User
+	result := tx.Where("tombstone").Limit(1).Find(&tombstone)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var taken int64
+		if err := tx.Model(&model.User{}).Where("username = ?", model.DeletedUsername).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrTombstoneUsernameTaken
+		}
+
+		// Пароль "!" не является bcrypt-хешем, поэтому войти под этим пользователем нельзя
+		tombstone = model.User{
+			Username:  model.DeletedUsername,
+			Email:     "deleted@localhost.invalid",
+			Password:  "!",
+			Role:      model.RoleUser,
+			Tombstone: true,
+		}
+		if err := tx.Create(&tombstone).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&model.Post{}).Where("author_id = ?", userID).
+		Update("author_id", tombstone.ID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.Comment{}).Where("author_id = ?", userID).
+		Update("author_id", tombstone.ID).Error
+}
+
+func deleteUserContent(tx *gorm.DB, userID int64) error {
+	// Сначала чужие комментарии к постам пользователя, чтобы не осталось сирот
+	if err := tx.Where("post_id IN (?)", tx.Model(&model.Post{}).Select("id").Where("author_id = ?", userID)).
+		Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("author_id = ?", userID).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("author_id = ?", userID).Delete(&model.Post{}).Error
+}
+
+func deleteUserData(tx *gorm.DB, userID int64) error {
+	ownedClients := func() *gorm.DB {
+		return tx.Model(&model.OAuthClient{}).Select("id").Where("owner_id = ?", userID)
+	}
+	if err := tx.Where("client_id IN (?)", ownedClients()).Delete(&model.OAuthAuthorizationCode{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("client_id IN (?)", ownedClients()).Delete(&model.OAuthRefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("client_id IN (?)", ownedClients()).Delete(&model.OAuthAccessToken{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_id = ?", userID).Delete(&model.OAuthClient{}).Error; err != nil {
+		return err
+	}
+
+	for _, table := range []interface{}{
+		&model.Session{},
+		&model.SecurityEvent{},
+		&model.PasswordResetToken{},
+		&model.RecoveryCode{},
+		&model.PersonalAccessToken{},
+		&model.UserIdentity{},
+		&model.OAuthAuthorizationCode{},
+		&model.OAuthRefreshToken{},
+		&model.OAuthAccessToken{},
+		&model.DataExport{},
+	} {
+		if err := tx.Where("user_id = ?", userID).Delete(table).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&model.User{}, userID).Error
+}
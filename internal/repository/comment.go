@@ -1,24 +1,43 @@
 package repository
 
 import (
-	"microblog/internal/database"
+	"gorm.io/gorm"
 	"microblog/internal/model"
 )
 
-func CreateComment(comment *model.Comment) (*model.Comment, error) {
-	result := database.DB.Create(comment)
+// CommentRepository хранит комментарии к постам
+type CommentRepository interface {
+	CreateComment(comment *model.Comment) (*model.Comment, error)
+	GetCommentsByPostID(postID int64, limit, offset int) ([]model.Comment, error)
+	GetCommentByID(id int64) (*model.Comment, error)
+	UpdateComment(id int64, comment *model.Comment) (*model.Comment, error)
+	DeleteComment(id int64) error
+	GetCommentsCountByPostID(postID int64) (int64, error)
+	GetCommentsByAuthor(authorID int64, limit, offset int) ([]model.Comment, error)
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) CreateComment(comment *model.Comment) (*model.Comment, error) {
+	result := r.db.Create(comment)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	database.DB.Preload("Author").First(comment, comment.ID)
+	r.db.Preload("Author").First(comment, comment.ID)
 
 	return comment, nil
 }
 
-func GetCommentsByPostID(postID int64, limit, offset int) ([]model.Comment, error) {
+func (r *commentRepository) GetCommentsByPostID(postID int64, limit, offset int) ([]model.Comment, error) {
 	var comments []model.Comment
-	result := database.DB.Preload("Author").
+	result := r.db.Preload("Author").
 		Where("post_id = ?", postID).
 		Order("created_at asc").
 		Limit(limit).
@@ -30,34 +49,48 @@ func GetCommentsByPostID(postID int64, limit, offset int) ([]model.Comment, erro
 	return comments, nil
 }
 
-func GetCommentByID(id int64) (*model.Comment, error) {
+func (r *commentRepository) GetCommentByID(id int64) (*model.Comment, error) {
 	var comment model.Comment
-	result := database.DB.Preload("Author").First(&comment, id)
+	result := r.db.Preload("Author").First(&comment, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &comment, nil
 }
 
-func UpdateComment(id int64, comment *model.Comment) (*model.Comment, error) {
-	result := database.DB.Model(&model.Comment{}).Where("id = ?", id).Updates(comment)
+func (r *commentRepository) UpdateComment(id int64, comment *model.Comment) (*model.Comment, error) {
+	result := r.db.Model(&model.Comment{}).Where("id = ?", id).Updates(comment)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
 	var updatedComment model.Comment
-	database.DB.Preload("Author").First(&updatedComment, id)
+	r.db.Preload("Author").First(&updatedComment, id)
 
 	return &updatedComment, nil
 }
 
-func DeleteComment(id int64) error {
-	result := database.DB.Delete(&model.Comment{}, id)
+func (r *commentRepository) DeleteComment(id int64) error {
+	result := r.db.Delete(&model.Comment{}, id)
 	return result.Error
 }
 
-func GetCommentsCountByPostID(postID int64) (int64, error) {
+func (r *commentRepository) GetCommentsCountByPostID(postID int64) (int64, error) {
 	var count int64
-	result := database.DB.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&count)
+	result := r.db.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&count)
 	return count, result.Error
 }
+
+func (r *commentRepository) GetCommentsByAuthor(authorID int64, limit, offset int) ([]model.Comment, error) {
+	var comments []model.Comment
+	result := r.db.
+		Where("author_id = ?", authorID).
+		Order("created_at asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&comments)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return comments, nil
+}
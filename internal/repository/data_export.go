@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// DataExportRepository хранит очередь выгрузок персональных данных
+type DataExportRepository interface {
+	CreateDataExport(export *model.DataExport) (*model.DataExport, error)
+	GetDataExportByID(id int64) (*model.DataExport, error)
+	GetLatestDataExport(userID int64) (*model.DataExport, error)
+	GetQueuedDataExports(staleBefore time.Time, limit int) ([]model.DataExport, error)
+	ClaimDataExport(export *model.DataExport, now time.Time) (bool, error)
+	CompleteDataExport(id int64, filePath string, fileSize int64, completedAt, expiresAt time.Time) error
+	FailDataExport(id int64, completedAt time.Time) error
+	GetExpiredDataExports(now time.Time) ([]model.DataExport, error)
+	DeleteDataExport(id int64) error
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) CreateDataExport(export *model.DataExport) (*model.DataExport, error) {
+	result := r.db.Create(export)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return export, nil
+}
+
+func (r *dataExportRepository) GetDataExportByID(id int64) (*model.DataExport, error) {
+	var export model.DataExport
+	result := r.db.First(&export, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) GetLatestDataExport(userID int64) (*model.DataExport, error) {
+	var export model.DataExport
+	result := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		First(&export)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+// GetQueuedDataExports возвращает ожидающие выгрузки, а также зависшие после падения экземпляра
+func (r *dataExportRepository) GetQueuedDataExports(staleBefore time.Time, limit int) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	result := r.db.Preload("User").
+		Where("status = ? OR (status = ? AND started_at < ?)", model.DataExportPending, model.DataExportProcessing, staleBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&exports)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return exports, nil
+}
+
+// ClaimDataExport забирает выгрузку в работу; false, если её уже взял другой экземпляр
+func (r *dataExportRepository) ClaimDataExport(export *model.DataExport, now time.Time) (bool, error) {
+	query := r.db.Model(&model.DataExport{}).Where("id = ? AND status = ?", export.ID, export.Status)
+	if export.StartedAt != nil {
+		query = query.Where("started_at = ?", *export.StartedAt)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"status":     model.DataExportProcessing,
+		"started_at": now,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *dataExportRepository) CompleteDataExport(id int64, filePath string, fileSize int64, completedAt, expiresAt time.Time) error {
+	result := r.db.Model(&model.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.DataExportReady,
+			"file_path":    filePath,
+			"file_size":    fileSize,
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+		})
+	return result.Error
+}
+
+func (r *dataExportRepository) FailDataExport(id int64, completedAt time.Time) error {
+	result := r.db.Model(&model.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.DataExportFailed,
+			"completed_at": completedAt,
+		})
+	return result.Error
+}
+
+func (r *dataExportRepository) GetExpiredDataExports(now time.Time) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	result := r.db.
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Find(&exports)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return exports, nil
+}
+
+func (r *dataExportRepository) DeleteDataExport(id int64) error {
+	result := r.db.Delete(&model.DataExport{}, id)
+	return result.Error
+}
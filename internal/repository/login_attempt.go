@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"microblog/internal/model"
+	"time"
+)
+
+// LoginAttemptRepository хранит счётчики неудачных попыток входа
+type LoginAttemptRepository interface {
+	GetLoginAttempt(key string) (*model.LoginAttempt, error)
+	UpdateLoginAttempt(key string, update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error)
+	DeleteLoginAttempt(key string) error
+	DeleteStaleLoginAttempts(before time.Time) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) GetLoginAttempt(key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	result := r.db.Where("key = ?", key).First(&attempt)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return &model.LoginAttempt{Key: key}, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &attempt, nil
+}
+
+// UpdateLoginAttempt блокирует строку счётчика на время update, чтобы параллельные
+// попытки с разных экземпляров сервиса не потеряли инкремент
+func (r *loginAttemptRepository) UpdateLoginAttempt(key string, update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{Key: key, LastFailure: time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			First(&attempt).Error; err != nil {
+			return err
+		}
+
+		update(&attempt)
+		return tx.Save(&attempt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempt(key string) error {
+	result := r.db.Where("key = ?", key).Delete(&model.LoginAttempt{})
+	return result.Error
+}
+
+func (r *loginAttemptRepository) DeleteStaleLoginAttempts(before time.Time) error {
+	result := r.db.
+		Where("last_failure < ? AND (blocked_until IS NULL OR blocked_until < ?)", before, time.Now()).
+		Delete(&model.LoginAttempt{})
+	return result.Error
+}
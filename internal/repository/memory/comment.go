@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type commentRepository struct {
+	*store
+}
+
+var commentsByCreatedAt = byCreatedAt(
+	func(comment model.Comment) time.Time { return comment.CreatedAt },
+	func(comment model.Comment) int64 { return comment.ID },
+)
+
+// commentsOfPost возвращает комментарии поста по возрастанию даты вместе с авторами
+func (s *store) commentsOfPost(postID int64) []model.Comment {
+	comments := sorted(s.comments, func(comment model.Comment) bool { return comment.PostID == postID }, commentsByCreatedAt)
+	for i := range comments {
+		comments[i].Author = s.users[comments[i].AuthorID]
+	}
+	return comments
+}
+
+func (s *store) countComments(postID int64) int64 {
+	var count int64
+	for _, comment := range s.comments {
+		if comment.PostID == postID {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *commentRepository) CreateComment(comment *model.Comment) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment.ID = r.nextID()
+	stamp(&comment.CreatedAt)
+	comment.UpdatedAt = comment.CreatedAt
+	r.comments[comment.ID] = *comment
+
+	comment.Author = r.users[comment.AuthorID]
+	return comment, nil
+}
+
+func (r *commentRepository) GetCommentsByPostID(postID int64, limit, offset int) ([]model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return page(r.commentsOfPost(postID), limit, offset), nil
+}
+
+func (r *commentRepository) GetCommentByID(id int64) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	comment.Author = r.users[comment.AuthorID]
+	return &comment, nil
+}
+
+func (r *commentRepository) UpdateComment(id int64, comment *model.Comment) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.comments[id]
+	if !ok {
+		return &model.Comment{}, nil
+	}
+
+	if comment.Content != "" {
+		updated.Content = comment.Content
+	}
+	updated.UpdatedAt = time.Now()
+	r.comments[id] = updated
+
+	updated.Author = r.users[updated.AuthorID]
+	return &updated, nil
+}
+
+func (r *commentRepository) DeleteComment(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.comments, id)
+	return nil
+}
+
+func (r *commentRepository) GetCommentsCountByPostID(postID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.countComments(postID), nil
+}
+
+func (r *commentRepository) GetCommentsByAuthor(authorID int64, limit, offset int) ([]model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comments := sorted(r.comments, func(comment model.Comment) bool { return comment.AuthorID == authorID }, commentsByCreatedAt)
+	return page(comments, limit, offset), nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type dataExportRepository struct {
+	*store
+}
+
+var exportsByCreatedAt = byCreatedAt(
+	func(export model.DataExport) time.Time { return export.CreatedAt },
+	func(export model.DataExport) int64 { return export.ID },
+)
+
+func (r *dataExportRepository) CreateDataExport(export *model.DataExport) (*model.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export.ID = r.nextID()
+	stamp(&export.CreatedAt)
+	r.dataExports[export.ID] = *export
+	return export, nil
+}
+
+func (r *dataExportRepository) GetDataExportByID(id int64) (*model.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	export, ok := r.dataExports[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) GetLatestDataExport(userID int64) (*model.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exports := sorted(r.dataExports, func(export model.DataExport) bool {
+		return export.UserID == userID
+	}, desc(exportsByCreatedAt))
+	if len(exports) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &exports[0], nil
+}
+
+func (r *dataExportRepository) GetQueuedDataExports(staleBefore time.Time, limit int) ([]model.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exports := page(sorted(r.dataExports, func(export model.DataExport) bool {
+		return export.Status == model.DataExportPending ||
+			(export.Status == model.DataExportProcessing && export.StartedAt != nil && export.StartedAt.Before(staleBefore))
+	}, exportsByCreatedAt), limit, 0)
+	for i := range exports {
+		exports[i].User = r.users[exports[i].UserID]
+	}
+	return exports, nil
+}
+
+func (r *dataExportRepository) ClaimDataExport(export *model.DataExport, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.dataExports[export.ID]
+	if !ok || stored.Status != export.Status {
+		return false, nil
+	}
+	if export.StartedAt != nil && (stored.StartedAt == nil || !stored.StartedAt.Equal(*export.StartedAt)) {
+		return false, nil
+	}
+
+	stored.Status = model.DataExportProcessing
+	stored.StartedAt = &now
+	r.dataExports[export.ID] = stored
+	return true, nil
+}
+
+func (r *dataExportRepository) CompleteDataExport(id int64, filePath string, fileSize int64, completedAt, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if export, ok := r.dataExports[id]; ok {
+		export.Status = model.DataExportReady
+		export.FilePath = filePath
+		export.FileSize = fileSize
+		export.CompletedAt = &completedAt
+		export.ExpiresAt = &expiresAt
+		r.dataExports[id] = export
+	}
+	return nil
+}
+
+func (r *dataExportRepository) FailDataExport(id int64, completedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if export, ok := r.dataExports[id]; ok {
+		export.Status = model.DataExportFailed
+		export.CompletedAt = &completedAt
+		r.dataExports[id] = export
+	}
+	return nil
+}
+
+func (r *dataExportRepository) GetExpiredDataExports(now time.Time) ([]model.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sorted(r.dataExports, func(export model.DataExport) bool {
+		return export.ExpiresAt != nil && !export.ExpiresAt.After(now)
+	}, exportsByCreatedAt), nil
+}
+
+func (r *dataExportRepository) DeleteDataExport(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.dataExports, id)
+	return nil
+}
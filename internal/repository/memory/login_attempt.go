@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type loginAttemptRepository struct {
+	*store
+}
+
+func (r *loginAttemptRepository) GetLoginAttempt(key string) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.loginAttempts[key]
+	if !ok {
+		return &model.LoginAttempt{Key: key}, nil
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) UpdateLoginAttempt(key string, update func(attempt *model.LoginAttempt)) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.loginAttempts[key]
+	if !ok {
+		attempt = model.LoginAttempt{Key: key, LastFailure: time.Now()}
+	}
+	update(&attempt)
+	r.loginAttempts[key] = attempt
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempt(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.loginAttempts, key)
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteStaleLoginAttempts(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	deleteWhere(r.loginAttempts, func(attempt model.LoginAttempt) bool {
+		return attempt.LastFailure.Before(before) && attempt.BlockedUntil.Before(now)
+	})
+	return nil
+}
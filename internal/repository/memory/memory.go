@@ -0,0 +1,135 @@
+// Package memory — реализация хранилищ в памяти процесса для тестов и локальных экспериментов.
+// Повторяет поведение Postgres-версии: те же ошибки, сортировки и подгрузка связанных записей.
+package memory
+
+import (
+	"cmp"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+// store — общее состояние всех хранилищ; как и у базы, удаление пользователя затрагивает все таблицы
+type store struct {
+	mu     sync.Mutex
+	lastID int64
+
+	users              map[int64]model.User
+	posts              map[int64]model.Post
+	comments           map[int64]model.Comment
+	sessions           map[int64]model.Session
+	securityEvents     map[int64]model.SecurityEvent
+	revokedTokens      map[string]time.Time
+	erasedUsers        map[string]model.ErasedUserRevocation
+	passwordResets     map[int64]model.PasswordResetToken
+	recoveryCodes      map[int64]model.RecoveryCode
+	loginAttempts      map[string]model.LoginAttempt
+	accessTokens       map[int64]model.PersonalAccessToken
+	identities         map[int64]model.UserIdentity
+	oauthClients       map[int64]model.OAuthClient
+	oauthCodes         map[int64]model.OAuthAuthorizationCode
+	oauthRefreshTokens map[int64]model.OAuthRefreshToken
+	oauthAccessTokens  map[int64]model.OAuthAccessToken
+	dataExports        map[int64]model.DataExport
+}
+
+// New создаёт пустой набор хранилищ в памяти
+func New() *repository.Repositories {
+	s := &store{
+		users:              make(map[int64]model.User),
+		posts:              make(map[int64]model.Post),
+		comments:           make(map[int64]model.Comment),
+		sessions:           make(map[int64]model.Session),
+		securityEvents:     make(map[int64]model.SecurityEvent),
+		revokedTokens:      make(map[string]time.Time),
+		erasedUsers:        make(map[string]model.ErasedUserRevocation),
+		passwordResets:     make(map[int64]model.PasswordResetToken),
+		recoveryCodes:      make(map[int64]model.RecoveryCode),
+		loginAttempts:      make(map[string]model.LoginAttempt),
+		accessTokens:       make(map[int64]model.PersonalAccessToken),
+		identities:         make(map[int64]model.UserIdentity),
+		oauthClients:       make(map[int64]model.OAuthClient),
+		oauthCodes:         make(map[int64]model.OAuthAuthorizationCode),
+		oauthRefreshTokens: make(map[int64]model.OAuthRefreshToken),
+		oauthAccessTokens:  make(map[int64]model.OAuthAccessToken),
+		dataExports:        make(map[int64]model.DataExport),
+	}
+
+	return &repository.Repositories{
+		Users:                &userRepository{s},
+		Posts:                &postRepository{s},
+		Comments:             &commentRepository{s},
+		Sessions:             &sessionRepository{s},
+		SecurityEvents:       &securityEventRepository{s},
+		Revocations:          &revocationRepository{s},
+		PasswordResets:       &passwordResetRepository{s},
+		TOTP:                 &totpRepository{s},
+		LoginAttempts:        &loginAttemptRepository{s},
+		PersonalAccessTokens: &personalAccessTokenRepository{s},
+		UserIdentities:       &userIdentityRepository{s},
+		OAuth:                &oauthRepository{s},
+		DataExports:          &dataExportRepository{s},
+	}
+}
+
+func (s *store) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+// sorted возвращает записи таблицы в порядке cmp; при равенстве — в порядке вставки, как по первичному ключу
+func sorted[T any](table map[int64]T, match func(T) bool, compare func(a, b T) int) []T {
+	var items []T
+	for _, item := range table {
+		if match == nil || match(item) {
+			items = append(items, item)
+		}
+	}
+	slices.SortStableFunc(items, compare)
+	return items
+}
+
+// byCreatedAt сравнивает записи по времени создания, затем по ID
+func byCreatedAt[T any](createdAt func(T) time.Time, id func(T) int64) func(a, b T) int {
+	return func(a, b T) int {
+		if c := createdAt(a).Compare(createdAt(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(id(a), id(b))
+	}
+}
+
+func desc[T any](compare func(a, b T) int) func(a, b T) int {
+	return func(a, b T) int {
+		return compare(b, a)
+	}
+}
+
+// page повторяет LIMIT/OFFSET
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func deleteWhere[K comparable, T any](table map[K]T, match func(T) bool) {
+	for key, item := range table {
+		if match(item) {
+			delete(table, key)
+		}
+	}
+}
+
+// stamp проставляет время создания, как это делает GORM
+func stamp(createdAt *time.Time) {
+	if createdAt.IsZero() {
+		*createdAt = time.Now()
+	}
+}
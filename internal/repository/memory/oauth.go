@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"time"
+)
+
+type oauthRepository struct {
+	*store
+}
+
+func (r *oauthRepository) CreateOAuthClient(client *model.OAuthClient) (*model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.oauthClients {
+		if existing.ClientID == client.ClientID {
+			return nil, gorm.ErrDuplicatedKey
+		}
+	}
+
+	client.ID = r.nextID()
+	stamp(&client.CreatedAt)
+	r.oauthClients[client.ID] = *client
+	return client, nil
+}
+
+func (r *oauthRepository) GetOAuthClientByClientID(clientID string) (*model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, client := range r.oauthClients {
+		if client.ClientID == clientID {
+			return &client, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *oauthRepository) GetOAuthClientByID(id int64) (*model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.oauthClients[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &client, nil
+}
+
+func (r *oauthRepository) GetOAuthClientsByOwnerID(ownerID int64) ([]model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sorted(r.oauthClients, func(client model.OAuthClient) bool {
+		return client.OwnerID == ownerID
+	}, desc(byCreatedAt(
+		func(client model.OAuthClient) time.Time { return client.CreatedAt },
+		func(client model.OAuthClient) int64 { return client.ID },
+	))), nil
+}
+
+func (r *oauthRepository) DeleteOAuthClient(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.oauthCodes, func(code model.OAuthAuthorizationCode) bool { return code.ClientID == id })
+	deleteWhere(r.oauthRefreshTokens, func(token model.OAuthRefreshToken) bool { return token.ClientID == id })
+	deleteWhere(r.oauthAccessTokens, func(token model.OAuthAccessToken) bool { return token.ClientID == id })
+	delete(r.oauthClients, id)
+	return nil
+}
+
+func (r *oauthRepository) CreateOAuthAuthorizationCode(code *model.OAuthAuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code.ID = r.nextID()
+	stamp(&code.CreatedAt)
+	r.oauthCodes[code.ID] = *code
+	return nil
+}
+
+func (r *oauthRepository) RedeemOAuthAuthorizationCode(codeHash string) (*model.OAuthAuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, code := range r.oauthCodes {
+		if code.CodeHash != codeHash || !code.ExpiresAt.After(now) {
+			continue
+		}
+
+		used := code.UsedAt != nil
+		if !used {
+			stored := code
+			stored.UsedAt = &now
+			r.oauthCodes[id] = stored
+		}
+
+		code.User = r.users[code.UserID]
+		code.Client = r.oauthClients[code.ClientID]
+		if used {
+			return &code, repository.ErrAuthorizationCodeUsed
+		}
+		return &code, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *oauthRepository) CreateOAuthRefreshToken(token *model.OAuthRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insertRefreshToken(token)
+	return nil
+}
+
+func (s *store) insertRefreshToken(token *model.OAuthRefreshToken) {
+	token.ID = s.nextID()
+	stamp(&token.CreatedAt)
+	s.oauthRefreshTokens[token.ID] = *token
+}
+
+func (r *oauthRepository) GetOAuthRefreshTokenByHash(tokenHash string) (*model.OAuthRefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.oauthRefreshTokens {
+		if token.TokenHash == tokenHash && token.ExpiresAt.After(now) {
+			token.User = r.users[token.UserID]
+			token.Client = r.oauthClients[token.ClientID]
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *oauthRepository) RotateOAuthRefreshToken(oldID int64, token *model.OAuthRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.oauthRefreshTokens[oldID]; !ok {
+		return repository.ErrRefreshTokenRotated
+	}
+	delete(r.oauthRefreshTokens, oldID)
+	r.insertRefreshToken(token)
+	return nil
+}
+
+func (r *oauthRepository) DeleteOAuthRefreshToken(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.oauthRefreshTokens, id)
+	return nil
+}
+
+func (r *oauthRepository) DeleteOAuthRefreshTokensByGrant(clientID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.oauthRefreshTokens, func(token model.OAuthRefreshToken) bool {
+		return token.ClientID == clientID && token.UserID == userID
+	})
+	return nil
+}
+
+func (r *oauthRepository) DeleteUserOAuthRefreshTokens(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.oauthRefreshTokens, func(token model.OAuthRefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (r *oauthRepository) CreateOAuthAccessToken(token *model.OAuthAccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.oauthAccessTokens {
+		if existing.TokenID == token.TokenID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	token.ID = r.nextID()
+	stamp(&token.CreatedAt)
+	r.oauthAccessTokens[token.ID] = *token
+	return nil
+}
+
+func (r *oauthRepository) GetOAuthAccessTokensByGrant(clientID, userID int64) ([]model.OAuthAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var tokens []model.OAuthAccessToken
+	for _, token := range r.oauthAccessTokens {
+		if token.ClientID == clientID && token.UserID == userID && token.ExpiresAt.After(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *oauthRepository) DeleteExpiredOAuthAccessTokens() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	deleteWhere(r.oauthAccessTokens, func(token model.OAuthAccessToken) bool { return !token.ExpiresAt.After(now) })
+	return nil
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"time"
+)
+
+type passwordResetRepository struct {
+	*store
+}
+
+func (r *passwordResetRepository) CreatePasswordResetToken(token *model.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.passwordResets, func(existing model.PasswordResetToken) bool {
+		return existing.UserID == token.UserID && existing.UsedAt == nil
+	})
+
+	token.ID = r.nextID()
+	stamp(&token.CreatedAt)
+	r.passwordResets[token.ID] = *token
+	return nil
+}
+
+func (r *passwordResetRepository) GetPasswordResetTokenByHash(tokenHash string) (*model.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.passwordResets {
+		if token.TokenHash == tokenHash && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.User = r.users[token.UserID]
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *passwordResetRepository) ResetUserPassword(token *model.PasswordResetToken, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.passwordResets[token.ID]
+	if !ok || stored.UsedAt != nil {
+		return repository.ErrResetTokenUsed
+	}
+	now := time.Now()
+	stored.UsedAt = &now
+	r.passwordResets[token.ID] = stored
+
+	r.updateUser(token.UserID, func(user *model.User) {
+		user.Password = passwordHash
+		user.EmailVerified = true
+	})
+	deleteWhere(r.oauthRefreshTokens, func(refresh model.OAuthRefreshToken) bool { return refresh.UserID == token.UserID })
+	deleteWhere(r.sessions, func(session model.Session) bool { return session.UserID == token.UserID })
+	return nil
+}
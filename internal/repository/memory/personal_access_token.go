@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type personalAccessTokenRepository struct {
+	*store
+}
+
+func (r *personalAccessTokenRepository) CreatePersonalAccessToken(token *model.PersonalAccessToken) (*model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.nextID()
+	stamp(&token.CreatedAt)
+	r.accessTokens[token.ID] = *token
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokenByHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.accessTokens {
+		if token.TokenHash == tokenHash && (token.ExpiresAt == nil || token.ExpiresAt.After(now)) {
+			token.User = r.users[token.UserID]
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokenByID(id int64) (*model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.accessTokens[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokensByUserID(userID int64) ([]model.PersonalAccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sorted(r.accessTokens, func(token model.PersonalAccessToken) bool {
+		return token.UserID == userID
+	}, desc(byCreatedAt(
+		func(token model.PersonalAccessToken) time.Time { return token.CreatedAt },
+		func(token model.PersonalAccessToken) int64 { return token.ID },
+	))), nil
+}
+
+func (r *personalAccessTokenRepository) TouchPersonalAccessToken(id int64, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.accessTokens[id]; ok {
+		token.LastUsedAt = &usedAt
+		r.accessTokens[id] = token
+	}
+	return nil
+}
+
+func (r *personalAccessTokenRepository) DeletePersonalAccessToken(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.accessTokens, id)
+	return nil
+}
+
+func (r *personalAccessTokenRepository) DeleteUserPersonalAccessTokens(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.accessTokens, func(token model.PersonalAccessToken) bool { return token.UserID == userID })
+	return nil
+}
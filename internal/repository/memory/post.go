@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type postRepository struct {
+	*store
+}
+
+var postsByCreatedAt = byCreatedAt(
+	func(post model.Post) time.Time { return post.CreatedAt },
+	func(post model.Post) int64 { return post.ID },
+)
+
+// loadPost подгружает автора и число комментариев, как Preload("Author") и подсчёт в базе
+func (s *store) loadPost(post model.Post) model.Post {
+	post.Author = s.users[post.AuthorID]
+	post.CommentsCount = s.countComments(post.ID)
+	return post
+}
+
+func (r *postRepository) CreatePost(post *model.Post) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post.ID = r.nextID()
+	stamp(&post.CreatedAt)
+	post.UpdatedAt = post.CreatedAt
+	post.Comments = nil
+	r.posts[post.ID] = *post
+
+	post.Author = r.users[post.AuthorID]
+	return post, nil
+}
+
+func (r *postRepository) GetPostByID(id int64) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	post = r.loadPost(post)
+	return &post, nil
+}
+
+func (r *postRepository) GetPostByIDWithComments(id int64, commentLimit, commentOffset int) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	post.Author = r.users[post.AuthorID]
+	post.Comments = page(r.commentsOfPost(post.ID), commentLimit, commentOffset)
+	post.CommentsCount = int64(len(post.Comments))
+	return &post, nil
+}
+
+func (r *postRepository) GetAllPosts(limit, offset int) ([]model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listPosts(nil, limit, offset), nil
+}
+
+func (r *postRepository) GetPostsByAuthor(authorID int64, limit, offset int) ([]model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listPosts(func(post model.Post) bool { return post.AuthorID == authorID }, limit, offset), nil
+}
+
+func (r *postRepository) listPosts(match func(model.Post) bool, limit, offset int) []model.Post {
+	posts := page(sorted(r.posts, match, desc(postsByCreatedAt)), limit, offset)
+	for i := range posts {
+		posts[i] = r.loadPost(posts[i])
+	}
+	return posts
+}
+
+func (r *postRepository) UpdatePost(id int64, post *model.Post) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.posts[id]
+	if !ok {
+		return &model.Post{}, nil
+	}
+
+	// Как Updates со структурой: пустые поля не меняются
+	if post.Title != "" {
+		updated.Title = post.Title
+	}
+	if post.Content != "" {
+		updated.Content = post.Content
+	}
+	updated.UpdatedAt = time.Now()
+	r.posts[id] = updated
+
+	updated.Author = r.users[updated.AuthorID]
+	return &updated, nil
+}
+
+func (r *postRepository) DeletePost(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.comments, func(comment model.Comment) bool { return comment.PostID == id })
+	delete(r.posts, id)
+	return nil
+}
+
+func (r *postRepository) GetPostsCountByAuthor(authorID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, post := range r.posts {
+		if post.AuthorID == authorID {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type revocationRepository struct {
+	*store
+}
+
+func (r *revocationRepository) CreateRevokedToken(tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *revocationRepository) IsTokenRevoked(tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expiresAt, ok := r.revokedTokens[tokenID]
+	return ok && expiresAt.After(time.Now()), nil
+}
+
+func (r *revocationRepository) DeleteExpiredRevokedTokens() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for tokenID, expiresAt := range r.revokedTokens {
+		if !expiresAt.After(now) {
+			delete(r.revokedTokens, tokenID)
+		}
+	}
+	return nil
+}
+
+func (r *revocationRepository) SetUserTokensValidAfter(username string, validAfter time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, err := r.findUser(func(user model.User) bool { return user.Username == username }); err == nil {
+		r.updateUser(user.ID, func(user *model.User) { user.TokensValidAfter = &validAfter })
+	}
+	return nil
+}
+
+func (r *revocationRepository) GetUserTokensValidAfter(username string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var validAfter *time.Time
+	if user, err := r.findUser(func(user model.User) bool { return user.Username == username }); err == nil {
+		validAfter = user.TokensValidAfter
+	}
+	if erased, ok := r.erasedUsers[username]; ok && erased.ExpiresAt.After(time.Now()) &&
+		(validAfter == nil || erased.ValidAfter.After(*validAfter)) {
+		validAfter = &erased.ValidAfter
+	}
+	return validAfter, nil
+}
+
+func (r *revocationRepository) SetErasedUserTokensValidAfter(username string, validAfter, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.erasedUsers[username] = model.ErasedUserRevocation{Username: username, ValidAfter: validAfter, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (r *revocationRepository) DeleteExpiredErasedUserRevocations() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for username, erased := range r.erasedUsers {
+		if !erased.ExpiresAt.After(now) {
+			delete(r.erasedUsers, username)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type securityEventRepository struct {
+	*store
+}
+
+func (r *securityEventRepository) CreateSecurityEvent(event *model.SecurityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = r.nextID()
+	stamp(&event.CreatedAt)
+	r.securityEvents[event.ID] = *event
+	return nil
+}
+
+func (r *securityEventRepository) GetSecurityEventsByUserID(userID int64) ([]model.SecurityEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sorted(r.securityEvents, func(event model.SecurityEvent) bool {
+		return event.UserID == userID
+	}, desc(byCreatedAt(
+		func(event model.SecurityEvent) time.Time { return event.CreatedAt },
+		func(event model.SecurityEvent) int64 { return event.ID },
+	))), nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"cmp"
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type sessionRepository struct {
+	*store
+}
+
+func (r *sessionRepository) CreateSession(session *model.Session) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.sessions {
+		if existing.FamilyID == session.FamilyID {
+			return nil, gorm.ErrDuplicatedKey
+		}
+	}
+
+	session.ID = r.nextID()
+	stamp(&session.CreatedAt)
+	r.sessions[session.ID] = *session
+	return session, nil
+}
+
+func (r *sessionRepository) GetSessionByID(id int64) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || !session.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetSessionByFamilyID(familyID string) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID && session.ExpiresAt.After(now) {
+			session.User = r.users[session.UserID]
+			return &session, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *sessionRepository) GetActiveSessionsByUserID(userID int64) ([]model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	return sorted(r.sessions, func(session model.Session) bool {
+		return session.UserID == userID && session.ExpiresAt.After(now)
+	}, func(a, b model.Session) int {
+		if c := b.LastUsedAt.Compare(a.LastUsedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+func (r *sessionRepository) AdvanceSessionSequence(id int64, sequence int, accessTokenID string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.Sequence != sequence {
+		return false, nil
+	}
+
+	session.Sequence = sequence + 1
+	session.AccessTokenID = accessTokenID
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = expiresAt
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *sessionRepository) DeleteSession(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *sessionRepository) DeleteUserSessions(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleteWhere(r.sessions, func(session model.Session) bool { return session.UserID == userID })
+	return nil
+}
+
+func (r *sessionRepository) SetSessionAccessTokenID(id int64, accessTokenID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok {
+		session.AccessTokenID = accessTokenID
+		r.sessions[id] = session
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"microblog/internal/model"
+	"time"
+)
+
+type totpRepository struct {
+	*store
+}
+
+func (r *totpRepository) SetUserTOTPSecret(userID int64, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.TOTPSecret = secret })
+	return nil
+}
+
+func (r *totpRepository) EnableUserTOTP(userID, step int64, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) {
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+	})
+	r.replaceRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+func (r *totpRepository) DisableUserTOTP(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) {
+		user.TOTPEnabled = false
+		user.TOTPSecret = ""
+		user.TOTPLastStep = 0
+	})
+	deleteWhere(r.recoveryCodes, func(code model.RecoveryCode) bool { return code.UserID == userID })
+	return nil
+}
+
+func (r *totpRepository) AdvanceUserTOTPStep(userID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.TOTPLastStep >= step {
+		return false, nil
+	}
+	r.updateUser(userID, func(user *model.User) { user.TOTPLastStep = step })
+	return true, nil
+}
+
+func (r *totpRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replaceRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+func (s *store) replaceRecoveryCodes(userID int64, codeHashes []string) {
+	deleteWhere(s.recoveryCodes, func(code model.RecoveryCode) bool { return code.UserID == userID })
+
+	now := time.Now()
+	for _, hash := range codeHashes {
+		id := s.nextID()
+		s.recoveryCodes[id] = model.RecoveryCode{ID: id, UserID: userID, CodeHash: hash, CreatedAt: now}
+	}
+}
+
+func (r *totpRepository) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, code := range r.recoveryCodes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			now := time.Now()
+			code.UsedAt = &now
+			r.recoveryCodes[id] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *totpRepository) CountUnusedRecoveryCodes(userID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, code := range r.recoveryCodes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"time"
+)
+
+type userRepository struct {
+	*store
+}
+
+// findUser ищет пользователя по условию; вызывается под блокировкой
+func (s *store) findUser(match func(model.User) bool) (*model.User, error) {
+	for _, user := range s.users {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *store) updateUser(userID int64, update func(user *model.User)) {
+	if user, ok := s.users[userID]; ok {
+		update(&user)
+		user.UpdatedAt = time.Now()
+		s.users[userID] = user
+	}
+}
+
+// insertUser сохраняет нового пользователя с проверкой уникальности, как индексы в базе
+func (s *store) insertUser(user *model.User) error {
+	for _, existing := range s.users {
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	if user.TokensValidAfter == nil {
+		now := time.Now()
+		user.TokensValidAfter = &now
+	}
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+	user.ID = s.nextID()
+	stamp(&user.CreatedAt)
+	user.UpdatedAt = user.CreatedAt
+	s.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) CreateUser(user *model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.insertUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetUserByUsername(username string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.findUser(func(user model.User) bool { return user.Username == username })
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.findUser(func(user model.User) bool { return user.Email == email })
+}
+
+func (r *userRepository) MarkUserEmailVerified(userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.EmailVerified = true })
+	return nil
+}
+
+func (r *userRepository) SetUserEmailVerificationSentAt(userID int64, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.EmailVerificationSentAt = &sentAt })
+	return nil
+}
+
+func (r *userRepository) UpdateUserPassword(userID int64, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.Password = passwordHash })
+	return nil
+}
+
+func (r *userRepository) SetUserPendingEmail(userID int64, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.PendingEmail = email })
+	return nil
+}
+
+func (r *userRepository) ChangeUserEmail(userID int64, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) {
+		user.Email = email
+		user.EmailVerified = true
+		user.PendingEmail = ""
+	})
+	return nil
+}
+
+func (r *userRepository) UpdateUserRole(userID int64, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.Role = role })
+	return nil
+}
+
+func (r *userRepository) UpdateUserProfile(userID int64, fields map[string]interface{}) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	for column, value := range fields {
+		text, _ := value.(string)
+		switch column {
+		case "display_name":
+			user.DisplayName = text
+		case "bio":
+			user.Bio = text
+		case "avatar_url":
+			user.AvatarURL = text
+		case "website":
+			user.Website = text
+		case "locale":
+			user.Locale = text
+		default:
+			return nil, fmt.Errorf("unknown profile column %q", column)
+		}
+	}
+	if len(fields) > 0 {
+		user.UpdatedAt = time.Now()
+		r.users[userID] = user
+	}
+	return &user, nil
+}
+
+func (r *userRepository) SetUserDisabledAt(userID int64, at *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.DisabledAt = at })
+	return nil
+}
+
+func (r *userRepository) ScheduleUserDeletion(userID int64, at *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.updateUser(userID, func(user *model.User) { user.DeletionScheduledAt = at })
+	return nil
+}
+
+func (r *userRepository) GetUsersDueForDeletion(now time.Time, limit, offset int) ([]model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := sorted(r.users, func(user model.User) bool {
+		return user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(now)
+	}, func(a, b model.User) int {
+		if c := a.DeletionScheduledAt.Compare(*b.DeletionScheduledAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return page(users, limit, offset), nil
+}
+
+func (r *userRepository) EraseUser(userID int64, anonymize bool, now time.Time) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(now) {
+		return nil, repository.ErrDeletionNotDue
+	}
+
+	if anonymize {
+		if err := r.reassignUserContent(userID); err != nil {
+			return nil, err
+		}
+	} else {
+		r.deleteUserContent(userID)
+	}
+	r.deleteUserData(userID)
+	return &user, nil
+}
+
+func (s *store) reassignUserContent(userID int64) error {
+	tombstone, err := s.findUser(func(user model.User) bool { return user.Tombstone })
+	if err != nil {
+		if _, err := s.findUser(func(user model.User) bool { return user.Username == model.DeletedUsername }); err == nil {
+			return repository.ErrTombstoneUsernameTaken
+		}
+		tombstone = &model.User{
+			Username:  model.DeletedUsername,
+			Email:     "deleted@localhost.invalid",
+			Password:  "!",
+			Role:      model.RoleUser,
+			Tombstone: true,
+		}
+		if err := s.insertUser(tombstone); err != nil {
+			return err
+		}
+	}
+
+	for id, post := range s.posts {
+		if post.AuthorID == userID {
+			post.AuthorID = tombstone.ID
+			s.posts[id] = post
+		}
+	}
+	for id, comment := range s.comments {
+		if comment.AuthorID == userID {
+			comment.AuthorID = tombstone.ID
+			s.comments[id] = comment
+		}
+	}
+	return nil
+}
+
+func (s *store) deleteUserContent(userID int64) {
+	deleteWhere(s.comments, func(comment model.Comment) bool {
+		return comment.AuthorID == userID || s.posts[comment.PostID].AuthorID == userID
+	})
+	deleteWhere(s.posts, func(post model.Post) bool { return post.AuthorID == userID })
+}
+
+func (s *store) deleteUserData(userID int64) {
+	ownClient := func(clientID int64) bool { return s.oauthClients[clientID].OwnerID == userID }
+	deleteWhere(s.oauthCodes, func(code model.OAuthAuthorizationCode) bool {
+		return code.UserID == userID || ownClient(code.ClientID)
+	})
+	deleteWhere(s.oauthRefreshTokens, func(token model.OAuthRefreshToken) bool {
+		return token.UserID == userID || ownClient(token.ClientID)
+	})
+	deleteWhere(s.oauthAccessTokens, func(token model.OAuthAccessToken) bool {
+		return token.UserID == userID || ownClient(token.ClientID)
+	})
+	deleteWhere(s.oauthClients, func(client model.OAuthClient) bool { return client.OwnerID == userID })
+
+	deleteWhere(s.sessions, func(session model.Session) bool { return session.UserID == userID })
+	deleteWhere(s.securityEvents, func(event model.SecurityEvent) bool { return event.UserID == userID })
+	deleteWhere(s.passwordResets, func(token model.PasswordResetToken) bool { return token.UserID == userID })
+	deleteWhere(s.recoveryCodes, func(code model.RecoveryCode) bool { return code.UserID == userID })
+	deleteWhere(s.accessTokens, func(token model.PersonalAccessToken) bool { return token.UserID == userID })
+	deleteWhere(s.identities, func(identity model.UserIdentity) bool { return identity.UserID == userID })
+	deleteWhere(s.dataExports, func(export model.DataExport) bool { return export.UserID == userID })
+
+	delete(s.users, userID)
+}
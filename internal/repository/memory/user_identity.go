@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+type userIdentityRepository struct {
+	*store
+}
+
+func (r *userIdentityRepository) GetUserIdentity(provider, subject string) (*model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			identity.User = r.users[identity.UserID]
+			return &identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userIdentityRepository) CreateUserIdentity(identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insertIdentity(identity)
+}
+
+func (r *userIdentityRepository) CreateUserWithIdentity(user *model.User, identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if err := r.insertUser(user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return r.insertIdentity(identity)
+}
+
+func (s *store) insertIdentity(identity *model.UserIdentity) error {
+	for _, existing := range s.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	identity.ID = s.nextID()
+	stamp(&identity.CreatedAt)
+	s.identities[identity.ID] = *identity
+	return nil
+}
+
+func (r *userIdentityRepository) TouchUserIdentity(id int64, email string, loginAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if identity, ok := r.identities[id]; ok {
+		identity.Email = email
+		identity.LastLoginAt = loginAt
+		r.identities[id] = identity
+	}
+	return nil
+}
+
+func (r *userIdentityRepository) GetUserIdentitiesByUserID(userID int64) ([]model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sorted(r.identities, func(identity model.UserIdentity) bool {
+		return identity.UserID == userID
+	}, byCreatedAt(
+		func(identity model.UserIdentity) time.Time { return identity.CreatedAt },
+		func(identity model.UserIdentity) int64 { return identity.ID },
+	)), nil
+}
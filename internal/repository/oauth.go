@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+var (
+	ErrAuthorizationCodeUsed = errors.New("authorization code already used")
+	ErrRefreshTokenRotated   = errors.New("refresh token already rotated")
+)
+
+// OAuthRepository хранит сторонние приложения, коды авторизации и выданные по ним токены
+type OAuthRepository interface {
+	CreateOAuthClient(client *model.OAuthClient) (*model.OAuthClient, error)
+	GetOAuthClientByClientID(clientID string) (*model.OAuthClient, error)
+	GetOAuthClientByID(id int64) (*model.OAuthClient, error)
+	GetOAuthClientsByOwnerID(ownerID int64) ([]model.OAuthClient, error)
+	DeleteOAuthClient(id int64) error
+	CreateOAuthAuthorizationCode(code *model.OAuthAuthorizationCode) error
+	RedeemOAuthAuthorizationCode(codeHash string) (*model.OAuthAuthorizationCode, error)
+	CreateOAuthRefreshToken(token *model.OAuthRefreshToken) error
+	GetOAuthRefreshTokenByHash(tokenHash string) (*model.OAuthRefreshToken, error)
+	RotateOAuthRefreshToken(oldID int64, token *model.OAuthRefreshToken) error
+	DeleteOAuthRefreshToken(id int64) error
+	DeleteOAuthRefreshTokensByGrant(clientID, userID int64) error
+	DeleteUserOAuthRefreshTokens(userID int64) error
+	CreateOAuthAccessToken(token *model.OAuthAccessToken) error
+	// GetOAuthAccessTokensByGrant возвращает ещё не истёкшие access-токены приложения для пользователя
+	GetOAuthAccessTokensByGrant(clientID, userID int64) ([]model.OAuthAccessToken, error)
+	DeleteExpiredOAuthAccessTokens() error
+}
+
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{db: db}
+}
+
+func (r *oauthRepository) CreateOAuthClient(client *model.OAuthClient) (*model.OAuthClient, error) {
+	result := r.db.Create(client)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return client, nil
+}
+
+func (r *oauthRepository) GetOAuthClientByClientID(clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	result := r.db.Where("client_id = ?", clientID).First(&client)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &client, nil
+}
+
+func (r *oauthRepository) GetOAuthClientByID(id int64) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	result := r.db.First(&client, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &client, nil
+}
+
+func (r *oauthRepository) GetOAuthClientsByOwnerID(ownerID int64) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	result := r.db.
+		Where("owner_id = ?", ownerID).
+		Order("created_at desc").
+		Find(&clients)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return clients, nil
+}
+
+// DeleteOAuthClient удаляет приложение вместе с выданными ему кодами и токенами
+func (r *oauthRepository) DeleteOAuthClient(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("client_id = ?", id).Delete(&model.OAuthAuthorizationCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", id).Delete(&model.OAuthRefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("client_id = ?", id).Delete(&model.OAuthAccessToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.OAuthClient{}, id).Error
+	})
+}
+
+func (r *oauthRepository) CreateOAuthAuthorizationCode(code *model.OAuthAuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// RedeemOAuthAuthorizationCode гасит код. Для уже использованного кода возвращает
+// его вместе с ErrAuthorizationCodeUsed, чтобы можно было отозвать выданные по нему токены.
+func (r *oauthRepository) RedeemOAuthAuthorizationCode(codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	result := r.db.Preload("User").Preload("Client").
+		Where("code_hash = ? AND expires_at > ?", codeHash, time.Now()).
+		First(&code)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	result = r.db.Model(&model.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return &code, ErrAuthorizationCodeUsed
+	}
+	return &code, nil
+}
+
+func (r *oauthRepository) CreateOAuthRefreshToken(token *model.OAuthRefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *oauthRepository) GetOAuthRefreshTokenByHash(tokenHash string) (*model.OAuthRefreshToken, error) {
+	var token model.OAuthRefreshToken
+	result := r.db.Preload("User").Preload("Client").
+		Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// RotateOAuthRefreshToken заменяет refresh-токен новым; параллельная ротация того же токена не пройдёт
+func (r *oauthRepository) RotateOAuthRefreshToken(oldID int64, token *model.OAuthRefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.OAuthRefreshToken{}, oldID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrRefreshTokenRotated
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *oauthRepository) DeleteOAuthRefreshToken(id int64) error {
+	result := r.db.Delete(&model.OAuthRefreshToken{}, id)
+	return result.Error
+}
+
+func (r *oauthRepository) DeleteOAuthRefreshTokensByGrant(clientID, userID int64) error {
+	result := r.db.
+		Where("client_id = ? AND user_id = ?", clientID, userID).
+		Delete(&model.OAuthRefreshToken{})
+	return result.Error
+}
+
+// DeleteUserOAuthRefreshTokens отзывает доступ всех приложений к аккаунту
+func (r *oauthRepository) DeleteUserOAuthRefreshTokens(userID int64) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.OAuthRefreshToken{})
+	return result.Error
+}
+
+func (r *oauthRepository) CreateOAuthAccessToken(token *model.OAuthAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *oauthRepository) GetOAuthAccessTokensByGrant(clientID, userID int64) ([]model.OAuthAccessToken, error) {
+	var tokens []model.OAuthAccessToken
+	result := r.db.
+		Where("client_id = ? AND user_id = ? AND expires_at > ?", clientID, userID, time.Now()).
+		Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+func (r *oauthRepository) DeleteExpiredOAuthAccessTokens() error {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&model.OAuthAccessToken{})
+	return result.Error
+}
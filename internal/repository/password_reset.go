@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+var ErrResetTokenUsed = errors.New("password reset token already used")
+
+// PasswordResetRepository хранит токены сброса пароля
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(token *model.PasswordResetToken) error
+	GetPasswordResetTokenByHash(tokenHash string) (*model.PasswordResetToken, error)
+	ResetUserPassword(token *model.PasswordResetToken, passwordHash string) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// CreatePasswordResetToken сохраняет новый токен и удаляет прежние неиспользованные токены пользователя
+func (r *passwordResetRepository) CreatePasswordResetToken(token *model.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Delete(&model.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *passwordResetRepository) GetPasswordResetTokenByHash(tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	result := r.db.Preload("User").
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// ResetUserPassword в одной транзакции гасит токен, меняет пароль и завершает все сессии
+// пользователя, включая доступ сторонних приложений
+func (r *passwordResetRepository) ResetUserPassword(token *model.PasswordResetToken, passwordHash string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrResetTokenUsed
+		}
+
+		// Ссылка пришла на почту, значит адрес подтверждён
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{
+				"password":       passwordHash,
+				"email_verified": true,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&model.OAuthRefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", token.UserID).Delete(&model.Session{}).Error
+	})
+}
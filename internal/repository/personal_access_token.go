@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// PersonalAccessTokenRepository хранит персональные токены доступа
+type PersonalAccessTokenRepository interface {
+	CreatePersonalAccessToken(token *model.PersonalAccessToken) (*model.PersonalAccessToken, error)
+	GetPersonalAccessTokenByHash(tokenHash string) (*model.PersonalAccessToken, error)
+	GetPersonalAccessTokenByID(id int64) (*model.PersonalAccessToken, error)
+	GetPersonalAccessTokensByUserID(userID int64) ([]model.PersonalAccessToken, error)
+	TouchPersonalAccessToken(id int64, usedAt time.Time) error
+	DeletePersonalAccessToken(id int64) error
+	DeleteUserPersonalAccessTokens(userID int64) error
+}
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+func (r *personalAccessTokenRepository) CreatePersonalAccessToken(token *model.PersonalAccessToken) (*model.PersonalAccessToken, error) {
+	result := r.db.Create(token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokenByHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	result := r.db.Preload("User").
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", tokenHash, time.Now()).
+		First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokenByID(id int64) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	result := r.db.First(&token, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+func (r *personalAccessTokenRepository) GetPersonalAccessTokensByUserID(userID int64) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	result := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+func (r *personalAccessTokenRepository) TouchPersonalAccessToken(id int64, usedAt time.Time) error {
+	result := r.db.Model(&model.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt)
+	return result.Error
+}
+
+func (r *personalAccessTokenRepository) DeletePersonalAccessToken(id int64) error {
+	result := r.db.Delete(&model.PersonalAccessToken{}, id)
+	return result.Error
+}
+
+func (r *personalAccessTokenRepository) DeleteUserPersonalAccessTokens(userID int64) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.PersonalAccessToken{})
+	return result.Error
+}
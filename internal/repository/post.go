@@ -1,59 +1,76 @@
 package repository
 
 import (
-	"microblog/internal/database"
+	"gorm.io/gorm"
 	"microblog/internal/model"
 )
 
-func CreatePost(post *model.Post) (*model.Post, error) {
-	result := database.DB.Create(post)
+// PostRepository хранит посты
+type PostRepository interface {
+	CreatePost(post *model.Post) (*model.Post, error)
+	GetPostByID(id int64) (*model.Post, error)
+	GetPostByIDWithComments(id int64, commentLimit, commentOffset int) (*model.Post, error)
+	GetAllPosts(limit, offset int) ([]model.Post, error)
+	GetPostsByAuthor(authorID int64, limit, offset int) ([]model.Post, error)
+	UpdatePost(id int64, post *model.Post) (*model.Post, error)
+	DeletePost(id int64) error
+	GetPostsCountByAuthor(authorID int64) (int64, error)
+}
+
+type postRepository struct {
+	db       *gorm.DB
+	comments *commentRepository
+}
+
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &postRepository{db: db, comments: &commentRepository{db: db}}
+}
+
+func (r *postRepository) CreatePost(post *model.Post) (*model.Post, error) {
+	result := r.db.Create(post)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	database.DB.Preload("Author").First(post, post.ID)
+	r.db.Preload("Author").First(post, post.ID)
 
 	return post, nil
 }
 
-func GetPostByID(id int64) (*model.Post, error) {
+func (r *postRepository) GetPostByID(id int64) (*model.Post, error) {
 	var post model.Post
-	result := database.DB.Preload("Author").First(&post, id)
+	result := r.db.Preload("Author").First(&post, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	count, _ := GetCommentsCountByPostID(post.ID)
+	count, _ := r.comments.GetCommentsCountByPostID(post.ID)
 	post.CommentsCount = count
 
 	return &post, nil
 }
 
-func GetPostByIDWithComments(id int64, commentLimit, commentOffset int) (*model.Post, error) {
+func (r *postRepository) GetPostByIDWithComments(id int64, commentLimit, commentOffset int) (*model.Post, error) {
 	var post model.Post
-	result := database.DB.Preload("Author").First(&post, id)
+	result := r.db.Preload("Author").First(&post, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	comments, err := GetCommentsByPostID(post.ID, commentLimit, commentOffset)
+	comments, err := r.comments.GetCommentsByPostID(post.ID, commentLimit, commentOffset)
 	if err != nil {
 		return nil, err
 	}
 
-	for i := range comments {
-		comments[i].Author.Password = ""
-	}
-
 	post.Comments = comments
 	post.CommentsCount = int64(len(comments))
 
 	return &post, nil
 }
 
-func GetAllPosts(limit, offset int) ([]model.Post, error) {
+func (r *postRepository) GetAllPosts(limit, offset int) ([]model.Post, error) {
 	var posts []model.Post
-	result := database.DB.Preload("Author").
+	result := r.db.Preload("Author").
 		Order("created_at desc").
 		Limit(limit).
 		Offset(offset).
@@ -63,16 +80,16 @@ func GetAllPosts(limit, offset int) ([]model.Post, error) {
 	}
 
 	for i := range posts {
-		count, _ := GetCommentsCountByPostID(posts[i].ID)
+		count, _ := r.comments.GetCommentsCountByPostID(posts[i].ID)
 		posts[i].CommentsCount = count
 	}
 
 	return posts, nil
 }
 
-func GetPostsByAuthor(authorID int64, limit, offset int) ([]model.Post, error) {
+func (r *postRepository) GetPostsByAuthor(authorID int64, limit, offset int) ([]model.Post, error) {
 	var posts []model.Post
-	result := database.DB.Preload("Author").
+	result := r.db.Preload("Author").
 		Where("author_id = ?", authorID).
 		Order("created_at desc").
 		Limit(limit).
@@ -83,26 +100,37 @@ func GetPostsByAuthor(authorID int64, limit, offset int) ([]model.Post, error) {
 	}
 
 	for i := range posts {
-		count, _ := GetCommentsCountByPostID(posts[i].ID)
+		count, _ := r.comments.GetCommentsCountByPostID(posts[i].ID)
 		posts[i].CommentsCount = count
 	}
 
 	return posts, nil
 }
 
-func UpdatePost(id int64, post *model.Post) (*model.Post, error) {
-	result := database.DB.Model(&model.Post{}).Where("id = ?", id).Updates(post)
+func (r *postRepository) UpdatePost(id int64, post *model.Post) (*model.Post, error) {
+	result := r.db.Model(&model.Post{}).Where("id = ?", id).Updates(post)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
 	var updatedPost model.Post
-	database.DB.Preload("Author").First(&updatedPost, id)
+	r.db.Preload("Author").First(&updatedPost, id)
 
 	return &updatedPost, nil
 }
 
-func DeletePost(id int64) error {
-	result := database.DB.Delete(&model.Post{}, id)
-	return result.Error
+// DeletePost удаляет пост вместе с комментариями к нему
+func (r *postRepository) DeletePost(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", id).Delete(&model.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Post{}, id).Error
+	})
+}
+
+func (r *postRepository) GetPostsCountByAuthor(authorID int64) (int64, error) {
+	var count int64
+	result := r.db.Model(&model.Post{}).Where("author_id = ?", authorID).Count(&count)
+	return count, result.Error
 }
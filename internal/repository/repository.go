@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Repositories собирает хранилища приложения; по умолчанию все работают поверх одного подключения к базе
+type Repositories struct {
+	Users                UserRepository
+	Posts                PostRepository
+	Comments             CommentRepository
+	Sessions             SessionRepository
+	SecurityEvents       SecurityEventRepository
+	Revocations          RevocationRepository
+	PasswordResets       PasswordResetRepository
+	TOTP                 TOTPRepository
+	LoginAttempts        LoginAttemptRepository
+	PersonalAccessTokens PersonalAccessTokenRepository
+	UserIdentities       UserIdentityRepository
+	OAuth                OAuthRepository
+	DataExports          DataExportRepository
+}
+
+// New создаёт хранилища поверх db; внутри транзакции можно передать tx
+func New(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:                NewUserRepository(db),
+		Posts:                NewPostRepository(db),
+		Comments:             NewCommentRepository(db),
+		Sessions:             NewSessionRepository(db),
+		SecurityEvents:       NewSecurityEventRepository(db),
+		Revocations:          NewRevocationRepository(db),
+		PasswordResets:       NewPasswordResetRepository(db),
+		TOTP:                 NewTOTPRepository(db),
+		LoginAttempts:        NewLoginAttemptRepository(db),
+		PersonalAccessTokens: NewPersonalAccessTokenRepository(db),
+		UserIdentities:       NewUserIdentityRepository(db),
+		OAuth:                NewOAuthRepository(db),
+		DataExports:          NewDataExportRepository(db),
+	}
+}
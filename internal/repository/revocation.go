@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// RevocationRepository хранит отозванные access-токены и границы отзыва пользователей
+type RevocationRepository interface {
+	CreateRevokedToken(tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(tokenID string) (bool, error)
+	DeleteExpiredRevokedTokens() error
+	SetUserTokensValidAfter(username string, validAfter time.Time) error
+	// GetUserTokensValidAfter учитывает и границу удалённого аккаунта с тем же именем
+	GetUserTokensValidAfter(username string) (*time.Time, error)
+	SetErasedUserTokensValidAfter(username string, validAfter, expiresAt time.Time) error
+	DeleteExpiredErasedUserRevocations() error
+}
+
+type revocationRepository struct {
+	db *gorm.DB
+}
+
+func NewRevocationRepository(db *gorm.DB) RevocationRepository {
+	return &revocationRepository{db: db}
+}
+
+func (r *revocationRepository) CreateRevokedToken(tokenID string, expiresAt time.Time) error {
+	result := r.db.Where(model.RevokedToken{TokenID: tokenID}).
+		Assign(model.RevokedToken{ExpiresAt: expiresAt}).
+		FirstOrCreate(&model.RevokedToken{})
+	return result.Error
+}
+
+func (r *revocationRepository) IsTokenRevoked(tokenID string) (bool, error) {
+	var count int64
+	result := r.db.Model(&model.RevokedToken{}).
+		Where("token_id = ? AND expires_at > ?", tokenID, time.Now()).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *revocationRepository) DeleteExpiredRevokedTokens() error {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&model.RevokedToken{})
+	return result.Error
+}
+
+func (r *revocationRepository) SetUserTokensValidAfter(username string, validAfter time.Time) error {
+	result := r.db.Model(&model.User{}).
+		Where("username = ?", username).
+		Update("tokens_valid_after", validAfter)
+	return result.Error
+}
+
+func (r *revocationRepository) GetUserTokensValidAfter(username string) (*time.Time, error) {
+	var user model.User
+	result := r.db.Select("tokens_valid_after").Where("username = ?", username).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var erased model.ErasedUserRevocation
+	result = r.db.Where("username = ? AND expires_at > ?", username, time.Now()).Limit(1).Find(&erased)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 && (user.TokensValidAfter == nil || erased.ValidAfter.After(*user.TokensValidAfter)) {
+		return &erased.ValidAfter, nil
+	}
+	return user.TokensValidAfter, nil
+}
+
+func (r *revocationRepository) SetErasedUserTokensValidAfter(username string, validAfter, expiresAt time.Time) error {
+	result := r.db.Where(model.ErasedUserRevocation{Username: username}).
+		Assign(model.ErasedUserRevocation{ValidAfter: validAfter, ExpiresAt: expiresAt}).
+		FirstOrCreate(&model.ErasedUserRevocation{})
+	return result.Error
+}
+
+func (r *revocationRepository) DeleteExpiredErasedUserRevocations() error {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&model.ErasedUserRevocation{})
+	return result.Error
+}
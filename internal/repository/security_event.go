@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+)
+
+// SecurityEventRepository хранит журнал событий безопасности
+type SecurityEventRepository interface {
+	CreateSecurityEvent(event *model.SecurityEvent) error
+	GetSecurityEventsByUserID(userID int64) ([]model.SecurityEvent, error)
+}
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{db: db}
+}
+
+func (r *securityEventRepository) CreateSecurityEvent(event *model.SecurityEvent) error {
+	result := r.db.Create(event)
+	return result.Error
+}
+
+func (r *securityEventRepository) GetSecurityEventsByUserID(userID int64) ([]model.SecurityEvent, error) {
+	var events []model.SecurityEvent
+	result := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&events)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return events, nil
+}
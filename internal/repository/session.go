@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// SessionRepository хранит сессии пользователей на устройствах
+type SessionRepository interface {
+	CreateSession(session *model.Session) (*model.Session, error)
+	GetSessionByID(id int64) (*model.Session, error)
+	GetSessionByFamilyID(familyID string) (*model.Session, error)
+	GetActiveSessionsByUserID(userID int64) ([]model.Session, error)
+	AdvanceSessionSequence(id int64, sequence int, accessTokenID string, expiresAt time.Time) (bool, error)
+	DeleteSession(id int64) error
+	DeleteUserSessions(userID int64) error
+	SetSessionAccessTokenID(id int64, accessTokenID string) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) CreateSession(session *model.Session) (*model.Session, error) {
+	result := r.db.Create(session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetSessionByID(id int64) (*model.Session, error) {
+	var session model.Session
+	result := r.db.Where("expires_at > ?", time.Now()).First(&session, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetSessionByFamilyID(familyID string) (*model.Session, error) {
+	var session model.Session
+	result := r.db.Preload("User").
+		Where("family_id = ? AND expires_at > ?", familyID, time.Now()).
+		First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetActiveSessionsByUserID(userID int64) ([]model.Session, error) {
+	var sessions []model.Session
+	result := r.db.
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return sessions, nil
+}
+
+// AdvanceSessionSequence сдвигает номер refresh-токена в семействе.
+// Возвращает false, если токен с этим номером уже был использован.
+func (r *sessionRepository) AdvanceSessionSequence(id int64, sequence int, accessTokenID string, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&model.Session{}).
+		Where("id = ? AND sequence = ?", id, sequence).
+		Updates(map[string]interface{}{
+			"sequence":        sequence + 1,
+			"access_token_id": accessTokenID,
+			"last_used_at":    time.Now(),
+			"expires_at":      expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *sessionRepository) DeleteSession(id int64) error {
+	result := r.db.Delete(&model.Session{}, id)
+	return result.Error
+}
+
+func (r *sessionRepository) DeleteUserSessions(userID int64) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&model.Session{})
+	return result.Error
+}
+
+func (r *sessionRepository) SetSessionAccessTokenID(id int64, accessTokenID string) error {
+	result := r.db.Model(&model.Session{}).
+		Where("id = ?", id).
+		Update("access_token_id", accessTokenID)
+	return result.Error
+}
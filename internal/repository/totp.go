@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// TOTPRepository хранит настройки двухфакторной аутентификации и коды восстановления
+type TOTPRepository interface {
+	SetUserTOTPSecret(userID int64, secret string) error
+	EnableUserTOTP(userID, step int64, codeHashes []string) error
+	DisableUserTOTP(userID int64) error
+	AdvanceUserTOTPStep(userID, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	UseRecoveryCode(userID int64, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID int64) (int64, error)
+}
+
+type totpRepository struct {
+	db *gorm.DB
+}
+
+func NewTOTPRepository(db *gorm.DB) TOTPRepository {
+	return &totpRepository{db: db}
+}
+
+func (r *totpRepository) SetUserTOTPSecret(userID int64, secret string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("totp_secret", secret)
+	return result.Error
+}
+
+// EnableUserTOTP включает 2FA и сохраняет хеши новых кодов восстановления
+func (r *totpRepository) EnableUserTOTP(userID, step int64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled":   true,
+				"totp_last_step": step,
+			}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *totpRepository) DisableUserTOTP(userID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"totp_enabled":   false,
+				"totp_secret":    "",
+				"totp_last_step": 0,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+}
+
+// AdvanceUserTOTPStep запоминает последний принятый шаг TOTP.
+// Возвращает false, если код этого шага уже был использован.
+func (r *totpRepository) AdvanceUserTOTPStep(userID, step int64) (bool, error) {
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *totpRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int64, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]model.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = model.RecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode гасит код восстановления; false — код не найден или уже использован
+func (r *totpRepository) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *totpRepository) CountUnusedRecoveryCodes(userID int64) (int64, error) {
+	var count int64
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count)
+	return count, result.Error
+}
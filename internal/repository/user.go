@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// UserRepository хранит пользователей и их учётные данные
+type UserRepository interface {
+	CreateUser(user *model.User) (*model.User, error)
+	GetUserByUsername(username string) (*model.User, error)
+	GetUserByEmail(email string) (*model.User, error)
+	MarkUserEmailVerified(userID int64) error
+	SetUserEmailVerificationSentAt(userID int64, sentAt time.Time) error
+	UpdateUserPassword(userID int64, passwordHash string) error
+	SetUserPendingEmail(userID int64, email string) error
+	ChangeUserEmail(userID int64, email string) error
+	UpdateUserRole(userID int64, role string) error
+	UpdateUserProfile(userID int64, fields map[string]interface{}) (*model.User, error)
+	ScheduleUserDeletion(userID int64, at *time.Time) error
+	SetUserDisabledAt(userID int64, at *time.Time) error
+	GetUsersDueForDeletion(now time.Time, limit, offset int) ([]model.User, error)
+	EraseUser(userID int64, anonymize bool, now time.Time) (*model.User, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) CreateUser(user *model.User) (*model.User, error) {
+	// Токены, выданные до регистрации (например, удалённому владельцу того же имени), недействительны
+	if user.TokensValidAfter == nil {
+		now := time.Now()
+		user.TokensValidAfter = &now
+	}
+
+	result := r.db.Create(user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetUserByUsername(username string) (*model.User, error) {
+	var user model.User
+	result := r.db.Where("username = ?", username).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*model.User, error) {
+	var user model.User
+	result := r.db.Where("email = ?", email).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *userRepository) MarkUserEmailVerified(userID int64) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("email_verified", true)
+	return result.Error
+}
+
+func (r *userRepository) SetUserEmailVerificationSentAt(userID int64, sentAt time.Time) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("email_verification_sent_at", sentAt)
+	return result.Error
+}
+
+func (r *userRepository) UpdateUserPassword(userID int64, passwordHash string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("password", passwordHash)
+	return result.Error
+}
+
+// SetUserDisabledAt отключает аккаунт; nil включает его обратно
+func (r *userRepository) SetUserDisabledAt(userID int64, at *time.Time) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("disabled_at", at)
+	return result.Error
+}
+
+func (r *userRepository) SetUserPendingEmail(userID int64, email string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("pending_email", email)
+	return result.Error
+}
+
+// ChangeUserEmail переносит подтверждённый адрес из pending_email в email
+func (r *userRepository) ChangeUserEmail(userID int64, email string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"email":          email,
+			"email_verified": true,
+			"pending_email":  "",
+		})
+	return result.Error
+}
+
+func (r *userRepository) UpdateUserRole(userID int64, role string) error {
+	result := r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Update("role", role)
+	return result.Error
+}
+
+// UpdateUserProfile обновляет только переданные поля профиля, в том числе пустыми значениями
+func (r *userRepository) UpdateUserProfile(userID int64, fields map[string]interface{}) (*model.User, error) {
+	if len(fields) > 0 {
+		result := r.db.Model(&model.User{ID: userID}).Updates(fields)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+
+	var user model.User
+	result := r.db.First(&user, userID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"microblog/internal/model"
+	"time"
+)
+
+// UserIdentityRepository хранит привязки аккаунтов к внешним провайдерам входа
+type UserIdentityRepository interface {
+	GetUserIdentity(provider, subject string) (*model.UserIdentity, error)
+	CreateUserIdentity(identity *model.UserIdentity) error
+	CreateUserWithIdentity(user *model.User, identity *model.UserIdentity) error
+	TouchUserIdentity(id int64, email string, loginAt time.Time) error
+	GetUserIdentitiesByUserID(userID int64) ([]model.UserIdentity, error)
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) GetUserIdentity(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	result := r.db.Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) CreateUserIdentity(identity *model.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity создаёт пользователя, пришедшего от провайдера, вместе с привязкой
+func (r *userIdentityRepository) CreateUserWithIdentity(user *model.User, identity *model.UserIdentity) error {
+	if user.TokensValidAfter == nil {
+		now := time.Now()
+		user.TokensValidAfter = &now
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *userIdentityRepository) TouchUserIdentity(id int64, email string, loginAt time.Time) error {
+	result := r.db.Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"email":         email,
+			"last_login_at": loginAt,
+		})
+	return result.Error
+}
+
+func (r *userIdentityRepository) GetUserIdentitiesByUserID(userID int64) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	result := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return identities, nil
+}
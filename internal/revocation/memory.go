@@ -0,0 +1,63 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит отзывы в памяти процесса; подходит только для одного экземпляра сервиса
+type MemoryStore struct {
+	mu         sync.RWMutex
+	tokens     map[string]time.Time
+	validAfter map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens:     make(map[string]time.Time),
+		validAfter: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Revoke(tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range s.tokens {
+		if !exp.After(now) {
+			delete(s.tokens, id)
+		}
+	}
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *MemoryStore) IsRevoked(tokenID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exp, ok := s.tokens[tokenID]
+	return ok && exp.After(time.Now()), nil
+}
+
+func (s *MemoryStore) RevokeUserTokens(username string, validAfter time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.validAfter[username] = validAfter
+	return nil
+}
+
+// Границы в памяти не привязаны к строке пользователя, поэтому удаление аккаунта их не стирает
+func (s *MemoryStore) RevokeErasedUserTokens(username string, validAfter, expiresAt time.Time) error {
+	return s.RevokeUserTokens(username, validAfter)
+}
+
+func (s *MemoryStore) UserTokensValidAfter(username string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.validAfter[username], nil
+}
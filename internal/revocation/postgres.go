@@ -0,0 +1,45 @@
+package revocation
+
+import (
+	"microblog/internal/repository"
+	"time"
+)
+
+// PostgresStore хранит отзывы в базе, поэтому они видны всем экземплярам сервиса
+type PostgresStore struct {
+	revocations repository.RevocationRepository
+}
+
+func NewPostgresStore(revocations repository.RevocationRepository) *PostgresStore {
+	return &PostgresStore{revocations: revocations}
+}
+
+func (s *PostgresStore) Revoke(tokenID string, expiresAt time.Time) error {
+	if err := s.revocations.DeleteExpiredRevokedTokens(); err != nil {
+		return err
+	}
+	return s.revocations.CreateRevokedToken(tokenID, expiresAt)
+}
+
+func (s *PostgresStore) IsRevoked(tokenID string) (bool, error) {
+	return s.revocations.IsTokenRevoked(tokenID)
+}
+
+func (s *PostgresStore) RevokeUserTokens(username string, validAfter time.Time) error {
+	return s.revocations.SetUserTokensValidAfter(username, validAfter)
+}
+
+func (s *PostgresStore) RevokeErasedUserTokens(username string, validAfter, expiresAt time.Time) error {
+	if err := s.revocations.DeleteExpiredErasedUserRevocations(); err != nil {
+		return err
+	}
+	return s.revocations.SetErasedUserTokensValidAfter(username, validAfter, expiresAt)
+}
+
+func (s *PostgresStore) UserTokensValidAfter(username string) (time.Time, error) {
+	validAfter, err := s.revocations.GetUserTokensValidAfter(username)
+	if err != nil || validAfter == nil {
+		return time.Time{}, err
+	}
+	return *validAfter, nil
+}
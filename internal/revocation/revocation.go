@@ -0,0 +1,82 @@
+package revocation
+
+import (
+	"fmt"
+	"microblog/internal/config"
+	"microblog/internal/repository"
+	"microblog/internal/util"
+	"time"
+)
+
+// Store хранит отозванные access-токены и границы отзыва по пользователям
+type Store interface {
+	// Revoke помечает токен отозванным до момента его истечения
+	Revoke(tokenID string, expiresAt time.Time) error
+	IsRevoked(tokenID string) (bool, error)
+	// RevokeUserTokens делает недействительными все токены пользователя, выданные раньше validAfter
+	RevokeUserTokens(username string, validAfter time.Time) error
+	// RevokeErasedUserTokens делает то же для удаляемого аккаунта; граница хранится отдельно
+	// от строки пользователя и действует до expiresAt, даже если имя займёт новый аккаунт
+	RevokeErasedUserTokens(username string, validAfter, expiresAt time.Time) error
+	UserTokensValidAfter(username string) (time.Time, error)
+}
+
+// Revoker отзывает access-токены и проверяет их при каждом запросе
+type Revoker struct {
+	store Store
+}
+
+// New выбирает хранилище отзывов по JWT_REVOCATION_STORE
+func New(cfg *config.Config, revocations repository.RevocationRepository) (*Revoker, error) {
+	switch cfg.JWT.RevocationStore {
+	case "memory":
+		return NewRevoker(NewMemoryStore()), nil
+	case "postgres":
+		return NewRevoker(NewPostgresStore(revocations)), nil
+	default:
+		return nil, fmt.Errorf("unknown revocation store %q", cfg.JWT.RevocationStore)
+	}
+}
+
+func NewRevoker(store Store) *Revoker {
+	return &Revoker{store: store}
+}
+
+func (r *Revoker) Revoke(tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return nil
+	}
+	return r.store.Revoke(tokenID, expiresAt)
+}
+
+func (r *Revoker) RevokeUserTokens(username string) error {
+	return r.store.RevokeUserTokens(username, time.Now())
+}
+
+// RevokeErasedUserTokens отзывает токены аккаунта перед удалением его строки;
+// запись живёт, пока не истечёт последний выданный до удаления access-токен
+func (r *Revoker) RevokeErasedUserTokens(username string) error {
+	now := time.Now()
+	return r.store.RevokeErasedUserTokens(username, now, now.Add(util.AccessTokenTTL))
+}
+
+// IsTokenRevoked проверяет и сам токен, и границу отзыва пользователя.
+// iat в JWT хранится с точностью до util.TokenTimePrecision, поэтому граница округляется вниз:
+// токены, выданные в ту же миллисекунду, что и отзыв, остаются действительными. При разборе
+// дробная часть iat проходит через float64 и может стать на одну единицу точности меньше,
+// поэтому к iat добавляется ещё одна единица.
+func (r *Revoker) IsTokenRevoked(tokenID, username string, issuedAt time.Time) (bool, error) {
+	if tokenID != "" {
+		revoked, err := r.store.IsRevoked(tokenID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	validAfter, err := r.store.UserTokensValidAfter(username)
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt.Add(util.TokenTimePrecision).Before(validAfter.Truncate(util.TokenTimePrecision)), nil
+}
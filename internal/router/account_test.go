@@ -0,0 +1,114 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	lifecycle "microblog/internal/account"
+	"microblog/internal/config"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErasedAccountTokens(t *testing.T) {
+	// Граница отзыва в строке users, как в Postgres: она пропадает вместе с удалённым аккаунтом
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.JWT.RevocationStore = "postgres"
+		cfg.Account.DeletionMode = lifecycle.DeletionModeDelete
+	})
+
+	alice := s.register("alice")
+	s.do(http.MethodDelete, "/api/me", alice.AccessToken, map[string]string{
+		"password": alice.Password,
+	}).expect(t, http.StatusAccepted, "")
+
+	if err := s.jobs.PurgeDueAccounts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.do(http.MethodGet, "/api/me", alice.AccessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+
+	// Старый токен не должен достаться новому владельцу имени
+	newAlice := s.register("alice")
+	s.do(http.MethodGet, "/api/me", alice.AccessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.do(http.MethodGet, "/api/me", newAlice.AccessToken, nil).expect(t, http.StatusOK, "")
+}
+
+func TestErasedAccountContentSkipsRegularDeletedUser(t *testing.T) {
+	s := newTestServer(t)
+
+	// Аккаунт с таким именем мог появиться до запрета на регистрацию
+	if _, err := s.repos.Users.CreateUser(&model.User{
+		Username: model.DeletedUsername,
+		Email:    "deleted@example.com",
+		Password: "hash",
+		Role:     model.RoleUser,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	alice := s.register("alice")
+	postID := s.createPost(alice, "Hello")
+	s.do(http.MethodDelete, "/api/me", alice.AccessToken, map[string]string{
+		"password": alice.Password,
+	}).expect(t, http.StatusAccepted, "")
+
+	// Удаление откладывается до переименования аккаунта, остальная работа задачи продолжается
+	err := s.jobs.PurgeDueAccounts(context.Background())
+	if !errors.Is(err, repository.ErrTombstoneUsernameTaken) {
+		t.Fatalf("expected ErrTombstoneUsernameTaken, got %v", err)
+	}
+
+	resp := s.do(http.MethodGet, fmt.Sprintf("/api/posts/%d", postID), "", nil)
+	resp.expect(t, http.StatusOK, "")
+	if author, _ := resp.object(t, "post")["author"].(map[string]interface{}); author["username"] != alice.Username {
+		t.Fatalf("post was reassigned to %v", author["username"])
+	}
+}
+
+// failingErasure не даёт удалить одного пользователя, как при сбое транзакции
+type failingErasure struct {
+	repository.UserRepository
+	userID int64
+}
+
+func (r failingErasure) EraseUser(userID int64, anonymize bool, now time.Time) (*model.User, error) {
+	if userID == r.userID {
+		return nil, errors.New("transaction aborted")
+	}
+	return r.UserRepository.EraseUser(userID, anonymize, now)
+}
+
+func TestPurgeContinuesAfterFailedErasure(t *testing.T) {
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.Account.DeletionMode = lifecycle.DeletionModeDelete
+	})
+
+	alice := s.register("alice")
+	bob := s.register("bob")
+	for _, acc := range []*account{alice, bob} {
+		s.do(http.MethodDelete, "/api/me", acc.AccessToken, map[string]string{
+			"password": acc.Password,
+		}).expect(t, http.StatusAccepted, "")
+	}
+
+	user, err := s.repos.Users.GetUserByUsername(alice.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.repos.Users = failingErasure{UserRepository: s.repos.Users, userID: user.ID}
+
+	// Ошибка с первым аккаунтом не мешает удалить второй, и повторный запуск не зацикливается на ней
+	for run := 0; run < 2; run++ {
+		err := s.jobs.PurgeDueAccounts(context.Background())
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("user %d", user.ID)) {
+			t.Fatalf("expected the erasure of alice to fail, got %v", err)
+		}
+	}
+
+	s.do(http.MethodGet, "/api/users/"+bob.Username, "", nil).expect(t, http.StatusNotFound, "user_not_found")
+	s.do(http.MethodGet, "/api/users/"+alice.Username, "", nil).expect(t, http.StatusOK, "")
+}
@@ -0,0 +1,118 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRegisterValidation(t *testing.T) {
+	s := newTestServer(t)
+
+	s.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"username": "al",
+		"email":    "not-an-email",
+		"password": "123",
+	}).expect(t, http.StatusBadRequest, "validation_failed")
+
+	s.register("alice")
+	s.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"username": "alice",
+		"email":    "other@example.com",
+		"password": "secret-other",
+	}).expect(t, http.StatusBadRequest, "username_taken")
+	s.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"username": "other",
+		"email":    "alice@example.com",
+		"password": "secret-other",
+	}).expect(t, http.StatusBadRequest, "email_taken")
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    alice.Email,
+		"password": "wrong-password",
+	}).expect(t, http.StatusUnauthorized, "invalid_credentials")
+
+	s.do(http.MethodGet, "/api/me", "", nil).expect(t, http.StatusUnauthorized, "")
+
+	resp := s.do(http.MethodGet, "/api/me", alice.AccessToken, nil)
+	resp.expect(t, http.StatusOK, "")
+	if username := resp.object(t, "user")["username"]; username != alice.Username {
+		t.Fatalf("expected username %q, got %v", alice.Username, username)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	resp := s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": alice.RefreshToken,
+	})
+	resp.expect(t, http.StatusOK, "")
+	accessToken := resp.string(t, "access_token")
+	refreshToken := resp.string(t, "refresh_token")
+	if refreshToken == alice.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	s.do(http.MethodGet, "/api/me", accessToken, nil).expect(t, http.StatusOK, "")
+
+	// Повторное использование старого токена отзывает всё семейство, включая новый токен
+	s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": alice.RefreshToken,
+	}).expect(t, http.StatusUnauthorized, "refresh_token_reuse")
+	s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": refreshToken,
+	}).expect(t, http.StatusUnauthorized, "invalid_or_expired_token")
+}
+
+func TestLogout(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	s.do(http.MethodPost, "/api/auth/logout", alice.AccessToken, nil).expect(t, http.StatusOK, "")
+
+	s.do(http.MethodGet, "/api/me", alice.AccessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": alice.RefreshToken,
+	}).expect(t, http.StatusUnauthorized, "invalid_or_expired_token")
+}
+
+func TestDisabledAccount(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	resp := s.do(http.MethodPost, "/api/tokens", alice.AccessToken, map[string]interface{}{
+		"name":   "cli",
+		"scopes": []string{"read"},
+	})
+	resp.expect(t, http.StatusCreated, "")
+	personalToken := resp.string(t, "token")
+
+	user, err := s.repos.Users.GetUserByUsername(alice.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := s.repos.Users.SetUserDisabledAt(user.ID, &now); err != nil {
+		t.Fatal(err)
+	}
+
+	s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    alice.Email,
+		"password": alice.Password,
+	}).expect(t, http.StatusForbidden, "account_disabled")
+	s.do(http.MethodGet, "/api/me", personalToken, nil).expect(t, http.StatusForbidden, "account_disabled")
+
+	// После включения персональный токен снова действует
+	if err := s.repos.Users.SetUserDisabledAt(user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	s.do(http.MethodGet, "/api/me", personalToken, nil).expect(t, http.StatusOK, "")
+	s.login(alice)
+}
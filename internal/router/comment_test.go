@@ -0,0 +1,143 @@
+package router_test
+
+import (
+	"fmt"
+	"microblog/internal/model"
+	"net/http"
+	"testing"
+)
+
+func (s *testServer) createComment(acc *account, postID int64, content string) int64 {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, fmt.Sprintf("/api/posts/%d/comments", postID), acc.AccessToken, map[string]string{
+		"content": content,
+	})
+	resp.expect(s.t, http.StatusCreated, "")
+	return id(s.t, resp.object(s.t, "comment"))
+}
+
+func TestCommentCRUD(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	postID := s.createPost(alice, "Hello")
+	commentsPath := fmt.Sprintf("/api/posts/%d/comments", postID)
+
+	s.do(http.MethodPost, "/api/posts/999/comments", bob.AccessToken, map[string]string{
+		"content": "Nice",
+	}).expect(t, http.StatusNotFound, "post_not_found")
+	s.do(http.MethodPost, commentsPath, bob.AccessToken, map[string]string{}).
+		expect(t, http.StatusBadRequest, "validation_failed")
+
+	commentID := s.createComment(bob, postID, "Nice")
+	path := fmt.Sprintf("/api/comments/%d", commentID)
+
+	resp := s.do(http.MethodGet, commentsPath, "", nil)
+	resp.expect(t, http.StatusOK, "")
+	comments := resp.list(t, "comments")
+	if len(comments) != 1 || comments[0].(map[string]interface{})["content"] != "Nice" {
+		t.Fatalf("unexpected comments: %v", comments)
+	}
+
+	resp = s.do(http.MethodPut, path, bob.AccessToken, map[string]string{"content": "Very nice"})
+	resp.expect(t, http.StatusOK, "")
+	if content := resp.object(t, "comment")["content"]; content != "Very nice" {
+		t.Fatalf("comment was not updated: %v", content)
+	}
+
+	resp = s.do(http.MethodGet, fmt.Sprintf("/api/posts/%d", postID), "", nil)
+	resp.expect(t, http.StatusOK, "")
+	if count := resp.object(t, "post")["comments_count"]; count != float64(1) {
+		t.Fatalf("expected comments_count 1, got %v", count)
+	}
+
+	s.do(http.MethodDelete, path, bob.AccessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodPut, path, bob.AccessToken, map[string]string{"content": "Again"}).
+		expect(t, http.StatusNotFound, "comment_not_found")
+	s.do(http.MethodDelete, "/api/comments/abc", bob.AccessToken, nil).expect(t, http.StatusBadRequest, "invalid_id")
+}
+
+func TestCommentOwnership(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	postID := s.createPost(alice, "Hello")
+	commentID := s.createComment(bob, postID, "Nice")
+	path := fmt.Sprintf("/api/comments/%d", commentID)
+
+	// Автор поста не управляет чужими комментариями под ним
+	s.do(http.MethodPut, path, alice.AccessToken, map[string]string{"content": "Edited"}).
+		expect(t, http.StatusForbidden, "not_owner")
+	s.do(http.MethodDelete, path, alice.AccessToken, nil).expect(t, http.StatusForbidden, "not_owner")
+
+	// Модератор может удалить, но не изменить чужой комментарий
+	s.setRole(alice, model.RoleModerator)
+	s.do(http.MethodPut, path, alice.AccessToken, map[string]string{"content": "Edited"}).
+		expect(t, http.StatusForbidden, "not_owner")
+	s.do(http.MethodDelete, path, alice.AccessToken, nil).expect(t, http.StatusOK, "")
+}
+
+func TestDeletePostRemovesComments(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	postID := s.createPost(alice, "Hello")
+	commentID := s.createComment(bob, postID, "Nice")
+
+	s.do(http.MethodDelete, fmt.Sprintf("/api/posts/%d", postID), alice.AccessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodDelete, fmt.Sprintf("/api/comments/%d", commentID), bob.AccessToken, nil).
+		expect(t, http.StatusNotFound, "comment_not_found")
+}
+
+func TestCommentPagination(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	postID := s.createPost(alice, "Hello")
+	for i := 0; i < 25; i++ {
+		s.createComment(alice, postID, fmt.Sprintf("Comment %d", i+1))
+	}
+
+	cases := []struct {
+		query string
+		count int
+	}{
+		{"", 20},
+		{"?limit=5", 5},
+		{"?offset=20", 5},
+		{"?limit=100", 25},
+		{"?limit=101", 20},
+		{"?limit=0", 20},
+		{"?offset=-1", 20},
+	}
+	for _, tc := range cases {
+		resp := s.do(http.MethodGet, fmt.Sprintf("/api/posts/%d/comments%s", postID, tc.query), "", nil)
+		resp.expect(t, http.StatusOK, "")
+		if comments := resp.list(t, "comments"); len(comments) != tc.count {
+			t.Fatalf("%s: expected %d comments, got %d", tc.query, tc.count, len(comments))
+		}
+	}
+
+	withComments := []struct {
+		query string
+		count int
+	}{
+		{"", 10},
+		{"?comment_limit=50", 25},
+		{"?comment_limit=51", 10},
+		{"?comment_limit=abc", 10},
+		{"?comment_offset=20", 5},
+	}
+	for _, tc := range withComments {
+		resp := s.do(http.MethodGet, fmt.Sprintf("/api/posts/%d/with-comments%s", postID, tc.query), "", nil)
+		resp.expect(t, http.StatusOK, "")
+		comments, _ := resp.object(t, "post")["comments"].([]interface{})
+		if len(comments) != tc.count {
+			t.Fatalf("with-comments%s: expected %d comments, got %d", tc.query, tc.count, len(comments))
+		}
+	}
+}
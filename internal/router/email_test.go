@@ -0,0 +1,69 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEmailChange(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	requestChange := func(acc *account, newEmail, password string) *response {
+		return s.do(http.MethodPost, "/api/me/email", acc.AccessToken, map[string]string{
+			"new_email": newEmail,
+			"password":  password,
+		})
+	}
+	confirmChange := func(acc *account, token string) *response {
+		return s.do(http.MethodPost, "/api/me/email/confirm", acc.AccessToken, map[string]string{
+			"token": token,
+		})
+	}
+
+	requestChange(alice, "alice@new.example.com", "wrong").expect(t, http.StatusBadRequest, "incorrect_password")
+	requestChange(alice, bob.Email, alice.Password).expect(t, http.StatusBadRequest, "email_taken")
+
+	// Действует только ссылка для последнего запрошенного адреса
+	requestChange(alice, "alice@old.example.com", alice.Password).expect(t, http.StatusOK, "")
+	stale := s.mail.token(t, "alice@old.example.com")
+	requestChange(alice, "alice@new.example.com", alice.Password).expect(t, http.StatusOK, "")
+	token := s.mail.token(t, "alice@new.example.com")
+	confirmChange(alice, stale).expect(t, http.StatusBadRequest, "invalid_or_expired_token")
+
+	// Ссылка подтверждает адрес только для того аккаунта, который его запросил
+	confirmChange(bob, token).expect(t, http.StatusBadRequest, "invalid_or_expired_token")
+
+	other := &account{Email: alice.Email, Password: alice.Password}
+	s.login(other)
+
+	resp := confirmChange(alice, token)
+	resp.expect(t, http.StatusOK, "")
+	accessToken := resp.string(t, "access_token")
+
+	// Текущая сессия продолжается с новым токеном, остальные завершены
+	me := s.do(http.MethodGet, "/api/me", accessToken, nil)
+	me.expect(t, http.StatusOK, "")
+	if user := me.object(t, "user"); user["email"] != "alice@new.example.com" {
+		t.Fatalf("email was not changed: %v", user)
+	}
+	s.do(http.MethodGet, "/api/me", other.AccessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": other.RefreshToken,
+	}).expect(t, http.StatusUnauthorized, "")
+
+	// Прежний адрес получает уведомление и больше не подходит для входа
+	if _, ok := s.mail.last(alice.Email, "Your email was changed"); !ok {
+		t.Fatalf("no notification to the old address %s", alice.Email)
+	}
+	s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    alice.Email,
+		"password": alice.Password,
+	}).expect(t, http.StatusUnauthorized, "invalid_credentials")
+	alice.Email = "alice@new.example.com"
+	s.login(alice)
+
+	// Повторно ссылка не срабатывает
+	confirmChange(alice, token).expect(t, http.StatusBadRequest, "invalid_or_expired_token")
+}
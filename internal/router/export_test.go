@@ -0,0 +1,94 @@
+package router_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// download скачивает архив выгрузки по ссылке и возвращает содержимое файлов в нём
+func (s *testServer) download(link string) map[string]string {
+	s.t.Helper()
+
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+	if w.Code != http.StatusOK {
+		s.t.Fatalf("expected the archive, got %d: %s", w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment") {
+		s.t.Fatalf("archive is not sent as an attachment: %q", disposition)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		files[file.Name] = string(data)
+	}
+	return files
+}
+
+func TestDataExport(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	s.createPost(alice, "Exported post")
+
+	resp := s.do(http.MethodPost, "/api/me/export", alice.AccessToken, nil)
+	resp.expect(t, http.StatusAccepted, "")
+	if export := resp.object(t, "export"); export["status"] != "pending" {
+		t.Fatalf("unexpected export: %v", export)
+	}
+	s.do(http.MethodPost, "/api/me/export", alice.AccessToken, nil).expect(t, http.StatusConflict, "export_in_progress")
+
+	resp = s.do(http.MethodGet, "/api/me/export", alice.AccessToken, nil)
+	resp.expect(t, http.StatusOK, "")
+	if _, ok := resp.Body["download_url"]; ok {
+		t.Fatalf("download link for an unfinished export: %v", resp.Body)
+	}
+
+	if err := s.jobs.ProcessDataExports(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	resp = s.do(http.MethodGet, "/api/me/export", alice.AccessToken, nil)
+	resp.expect(t, http.StatusOK, "")
+	if export := resp.object(t, "export"); export["status"] != "ready" {
+		t.Fatalf("export is not ready: %v", export)
+	}
+	link, err := url.Parse(resp.string(t, "download_url"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ссылка из письма и ссылка из статуса ведут к одному архиву
+	token := s.mail.token(t, alice.Email)
+	files := s.download("/api/exports/download?" + url.Values{"token": {token}}.Encode())
+	if !strings.Contains(files["posts.json"], "Exported post") || !strings.Contains(files["profile.json"], alice.Email) {
+		t.Fatalf("unexpected archive contents: %v", files)
+	}
+	if again := s.download(link.RequestURI()); again["posts.json"] != files["posts.json"] {
+		t.Fatal("download link from the export status returned another archive")
+	}
+
+	s.do(http.MethodGet, "/api/exports/download?token=forged", "", nil).expect(t, http.StatusBadRequest, "invalid_or_expired_token")
+
+	// Готовая выгрузка не мешает запросить новую
+	s.do(http.MethodPost, "/api/me/export", alice.AccessToken, nil).expect(t, http.StatusAccepted, "")
+}
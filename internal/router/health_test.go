@@ -0,0 +1,69 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"microblog/internal/handler"
+	"net/http"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	s := newTestServer(t, handler.HealthCheck{
+		Name:  "database",
+		Check: func(context.Context) error { return errors.New("connection refused") },
+	})
+
+	// Живость не зависит от внешних зависимостей
+	resp := s.do(http.MethodGet, "/healthz", "", nil)
+	resp.expect(t, http.StatusOK, "")
+	if resp.Body["status"] != "ok" {
+		t.Fatalf("unexpected status: %v", resp.Body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	var databaseErr error
+	s := newTestServer(t, handler.HealthCheck{
+		Name:  "database",
+		Check: func(context.Context) error { return databaseErr },
+	})
+
+	resp := s.do(http.MethodGet, "/readyz", "", nil)
+	resp.expect(t, http.StatusOK, "")
+	if resp.Body["status"] != "ok" {
+		t.Fatalf("unexpected status: %v", resp.Body)
+	}
+	components := resp.object(t, "components")
+	for _, name := range []string{"database", "workers"} {
+		if component, _ := components[name].(map[string]interface{}); component["status"] != "ok" {
+			t.Fatalf("expected %s to be ok: %v", name, components)
+		}
+	}
+
+	databaseErr = errors.New("connection refused")
+	resp = s.do(http.MethodGet, "/readyz", "", nil)
+	resp.expect(t, http.StatusServiceUnavailable, "")
+	components = resp.object(t, "components")
+	// Причина сбоя может содержать адрес базы и остаётся в логе сервера
+	if database, _ := components["database"].(map[string]interface{}); database["status"] != "fail" || len(database) != 1 {
+		t.Fatalf("expected database to fail without details: %v", components)
+	}
+	if workers, _ := components["workers"].(map[string]interface{}); workers["status"] != "ok" {
+		t.Fatalf("expected workers to be ok: %v", components)
+	}
+}
+
+func TestReadyzDuringShutdown(t *testing.T) {
+	s := newTestServer(t)
+	s.do(http.MethodGet, "/readyz", "", nil).expect(t, http.StatusOK, "")
+
+	s.handlers.Health.Drain()
+
+	resp := s.do(http.MethodGet, "/readyz", "", nil)
+	resp.expect(t, http.StatusServiceUnavailable, "")
+	if server, _ := resp.object(t, "components")["server"].(map[string]interface{}); server["status"] != "fail" {
+		t.Fatalf("expected server to fail: %v", resp.Body)
+	}
+	s.do(http.MethodGet, "/healthz", "", nil).expect(t, http.StatusOK, "")
+}
@@ -0,0 +1,101 @@
+package router_test
+
+import (
+	"fmt"
+	"microblog/internal/config"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// failLogins делает n неудачных попыток входа с разными адресами почты
+func (s *testServer) failLogins(n int, header func(i int) http.Header) *response {
+	s.t.Helper()
+
+	var resp *response
+	for i := 0; i < n; i++ {
+		resp = s.doWithHeader(http.MethodPost, "/api/auth/login", "", map[string]string{
+			"email":    fmt.Sprintf("nobody%d@example.com", i),
+			"password": "wrong",
+		}, header(i))
+	}
+	return resp
+}
+
+func spoofedForwardedFor(i int) http.Header {
+	return http.Header{"X-Forwarded-For": {fmt.Sprintf("203.0.113.%d", i+1)}}
+}
+
+func TestLoginIPThrottleIgnoresUntrustedForwardedFor(t *testing.T) {
+	s := newTestServer(t)
+
+	// Бесплатных попыток с одного IP десять, после одиннадцатой включается задержка
+	s.failLogins(11, spoofedForwardedFor)
+	resp := s.failLogins(1, spoofedForwardedFor)
+	resp.expect(t, http.StatusTooManyRequests, "too_many_attempts")
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatal("Retry-After header is missing")
+	}
+}
+
+func TestLoginIPThrottleUsesForwardedForFromTrustedProxy(t *testing.T) {
+	// httptest присылает запросы с адреса 192.0.2.1
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.Server.TrustedProxies = []string{"192.0.2.1"}
+	})
+
+	s.failLogins(11, spoofedForwardedFor)
+	s.failLogins(1, spoofedForwardedFor).expect(t, http.StatusUnauthorized, "invalid_credentials")
+}
+
+func (s *testServer) loginAttempt(email, password string) *response {
+	s.t.Helper()
+
+	return s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    email,
+		"password": password,
+	})
+}
+
+func TestLoginAccountDelay(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	// Три попытки бесплатны, после четвёртой вход откладывается даже с верным паролем
+	for i := 0; i < 4; i++ {
+		s.loginAttempt(alice.Email, "wrong").expect(t, http.StatusUnauthorized, "invalid_credentials")
+	}
+	resp := s.loginAttempt(alice.Email, alice.Password)
+	resp.expect(t, http.StatusTooManyRequests, "too_many_attempts")
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "1" {
+		t.Fatalf("expected Retry-After: 1, got %q", retryAfter)
+	}
+
+	// Задержка относится к аккаунту, а не ко всем входам с этого IP
+	bob := s.register("bob")
+	s.login(bob)
+}
+
+func TestLoginAccountLockout(t *testing.T) {
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.Lockout.MaxFailures = 3
+	})
+	alice := s.register("alice")
+	admin := s.register("admin")
+	s.setRole(admin, "admin")
+
+	for i := 0; i < 3; i++ {
+		s.loginAttempt(alice.Email, "wrong").expect(t, http.StatusUnauthorized, "invalid_credentials")
+	}
+	resp := s.loginAttempt(alice.Email, alice.Password)
+	resp.expect(t, http.StatusLocked, "account_locked")
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || retryAfter <= 14*60 || retryAfter > 15*60 {
+		t.Fatalf("expected Retry-After close to the lockout duration, got %q", resp.Header.Get("Retry-After"))
+	}
+
+	// Блокировку снимает администратор
+	s.do(http.MethodPost, "/api/admin/users/alice/unlock", alice.AccessToken, nil).expect(t, http.StatusForbidden, "")
+	s.do(http.MethodPost, "/api/admin/users/alice/unlock", admin.AccessToken, nil).expect(t, http.StatusOK, "")
+	s.login(alice)
+}
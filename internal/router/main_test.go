@@ -0,0 +1,336 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	lifecycle "microblog/internal/account"
+	"microblog/internal/app"
+	"microblog/internal/config"
+	"microblog/internal/handler"
+	"microblog/internal/mailer"
+	"microblog/internal/repository"
+	"microblog/internal/repository/memory"
+	"microblog/internal/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// testServer — приложение целиком поверх хранилищ в памяти; тесты общаются с ним только по HTTP
+type testServer struct {
+	t        *testing.T
+	engine   *gin.Engine
+	repos    *repository.Repositories
+	jobs     *lifecycle.Jobs
+	mail     *mailbox
+	handlers *handler.Handlers
+}
+
+func newTestServer(t *testing.T, checks ...handler.HealthCheck) *testServer {
+	t.Helper()
+	return newTestServerWith(t, func(*config.Config) {}, checks...)
+}
+
+// newTestServerWith даёт тесту поменять конфигурацию до сборки приложения
+func newTestServerWith(t *testing.T, configure func(cfg *config.Config), checks ...handler.HealthCheck) *testServer {
+	t.Helper()
+
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:          "test-access-secret",
+			RefreshSecret:   "test-refresh-secret",
+			ActionSecret:    "test-action-secret",
+			RevocationStore: "memory",
+			Algorithm:       "HS256",
+		},
+		Server: config.ServerConfig{PublicURL: "http://microblog.test"},
+		Mail:   config.MailConfig{Driver: "log"},
+		Lockout: config.LockoutConfig{
+			Store:           "memory",
+			MaxFailures:     10,
+			IPMaxFailures:   50,
+			LockoutDuration: 15 * time.Minute,
+		},
+		Account: config.AccountConfig{
+			DeletionMode: lifecycle.DeletionModeAnonymize,
+			ExportDir:    t.TempDir(),
+			ExportTTL:    time.Hour,
+		},
+	}
+	configure(cfg)
+
+	if err := util.InitJWT(cfg); err != nil {
+		t.Fatal(err)
+	}
+	repos := memory.New()
+	mail := &mailbox{}
+	a, err := app.New(cfg, repos, mail, checks...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testServer{
+		t:        t,
+		engine:   a.Engine,
+		repos:    repos,
+		jobs:     a.Lifecycle,
+		mail:     mail,
+		handlers: a.Handlers,
+	}
+}
+
+// response — ответ сервера с разобранным JSON-телом
+type response struct {
+	Status int
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+func (s *testServer) do(method, path, token string, body interface{}) *response {
+	s.t.Helper()
+	return s.doWithHeader(method, path, token, body, nil)
+}
+
+func (s *testServer) doWithHeader(method, path, token string, body interface{}, header http.Header) *response {
+	s.t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return s.send(req)
+}
+
+// send выполняет готовый запрос и разбирает JSON-ответ
+func (s *testServer) send(req *http.Request) *response {
+	s.t.Helper()
+
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+
+	resp := &response{Status: w.Code, Header: w.Header()}
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp.Body); err != nil {
+			s.t.Fatalf("%s %s: invalid JSON response %q: %v", req.Method, req.URL.Path, w.Body.String(), err)
+		}
+	}
+	return resp
+}
+
+// expect проверяет статус ответа и, для ошибок, стабильный код из тела
+func (r *response) expect(t *testing.T, status int, code string) {
+	t.Helper()
+
+	if r.Status != status {
+		t.Fatalf("expected status %d, got %d: %v", status, r.Status, r.Body)
+	}
+	if code != "" && r.Body["code"] != code {
+		t.Fatalf("expected error code %q, got %v", code, r.Body["code"])
+	}
+}
+
+func (r *response) object(t *testing.T, key string) map[string]interface{} {
+	t.Helper()
+
+	value, ok := r.Body[key].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no object %q: %v", key, r.Body)
+	}
+	return value
+}
+
+func (r *response) list(t *testing.T, key string) []interface{} {
+	t.Helper()
+
+	value, ok := r.Body[key].([]interface{})
+	if !ok && r.Body[key] != nil {
+		t.Fatalf("response has no list %q: %v", key, r.Body)
+	}
+	return value
+}
+
+func (r *response) string(t *testing.T, key string) string {
+	t.Helper()
+
+	value, ok := r.Body[key].(string)
+	if !ok || value == "" {
+		t.Fatalf("response has no string %q: %v", key, r.Body)
+	}
+	return value
+}
+
+// id достаёт числовой идентификатор из JSON-объекта
+func id(t *testing.T, object map[string]interface{}) int64 {
+	t.Helper()
+
+	value, ok := object["id"].(float64)
+	if !ok {
+		t.Fatalf("object has no id: %v", object)
+	}
+	return int64(value)
+}
+
+// account — зарегистрированный пользователь с подтверждённым адресом и открытой сессией
+type account struct {
+	Username     string
+	Email        string
+	Password     string
+	AccessToken  string
+	RefreshToken string
+}
+
+func (s *testServer) register(username string) *account {
+	s.t.Helper()
+
+	acc := &account{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: "secret-" + username,
+	}
+	s.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"username": acc.Username,
+		"email":    acc.Email,
+		"password": acc.Password,
+	}).expect(s.t, http.StatusCreated, "")
+
+	s.do(http.MethodPost, "/api/auth/verify-email", "", map[string]string{
+		"token": s.mail.token(s.t, acc.Email),
+	}).expect(s.t, http.StatusOK, "")
+
+	s.login(acc)
+	return acc
+}
+
+func (s *testServer) login(acc *account) {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    acc.Email,
+		"password": acc.Password,
+	})
+	resp.expect(s.t, http.StatusOK, "")
+	acc.AccessToken = resp.string(s.t, "access_token")
+	acc.RefreshToken = resp.string(s.t, "refresh_token")
+}
+
+// setRole меняет роль напрямую в хранилище; новая роль попадает в токен при следующем входе
+func (s *testServer) setRole(acc *account, role string) {
+	s.t.Helper()
+
+	user, err := s.repos.Users.GetUserByUsername(acc.Username)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.repos.Users.UpdateUserRole(user.ID, role); err != nil {
+		s.t.Fatal(err)
+	}
+	s.login(acc)
+}
+
+func (s *testServer) createPost(acc *account, title string) int64 {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/posts", acc.AccessToken, map[string]string{
+		"title":   title,
+		"content": "Content of " + title,
+	})
+	resp.expect(s.t, http.StatusCreated, "")
+	return id(s.t, resp.object(s.t, "post"))
+}
+
+// mailbox собирает письма вместо отправки
+type mailbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *mailbox) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+var linkTokenRegex = regexp.MustCompile(`[?&]token=([^\s&]+)`)
+
+// token возвращает токен из ссылки в последнем письме на адрес to
+func (m *mailbox) token(t *testing.T, to string) string {
+	t.Helper()
+
+	msg, ok := m.last(to, "")
+	if !ok {
+		t.Fatalf("no mail to %s", to)
+	}
+	return linkToken(t, msg)
+}
+
+// awaitToken дожидается письма на адрес to с темой subject, которое отправляется в фоне,
+// и возвращает токен из ссылки в нём
+func (m *mailbox) awaitToken(t *testing.T, to, subject string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if msg, ok := m.last(to, subject); ok {
+			return linkToken(t, msg)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no mail %q to %s", subject, to)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// last возвращает последнее письмо на адрес to; пустой subject подходит к любой теме
+func (m *mailbox) last(to, subject string) (mailer.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(m.messages[i].To, to) && (subject == "" || m.messages[i].Subject == subject) {
+			return m.messages[i], true
+		}
+	}
+	return mailer.Message{}, false
+}
+
+func linkToken(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+
+	match := linkTokenRegex.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no link in the mail to %s: %q", msg.To, msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -0,0 +1,303 @@
+package router_test
+
+import (
+	"microblog/internal/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const oauthRedirectURI = "https://app.example.com/callback"
+
+// oauthClient — стороннее приложение, зарегистрированное владельцем аккаунта
+type oauthClient struct {
+	ClientID string
+	Secret   string
+}
+
+func (s *testServer) registerOAuthClient(owner *account, confidential bool) *oauthClient {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/oauth/clients", owner.AccessToken, map[string]interface{}{
+		"name":          "Reader",
+		"redirect_uris": []string{oauthRedirectURI},
+		"scopes":        []string{"read", "posts:write"},
+		"confidential":  confidential,
+	})
+	resp.expect(s.t, http.StatusCreated, "")
+
+	client := &oauthClient{ClientID: resp.object(s.t, "client")["client_id"].(string)}
+	if confidential {
+		client.Secret = resp.string(s.t, "client_secret")
+	}
+	return client
+}
+
+// authorize проходит экран согласия от имени acc и возвращает код авторизации;
+// пустой redirectURI — адрес возврата не указан
+func (s *testServer) authorize(acc *account, client *oauthClient, verifier, redirectURI string) string {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/oauth/authorize", acc.AccessToken, map[string]interface{}{
+		"response_type":         "code",
+		"client_id":             client.ClientID,
+		"redirect_uri":          redirectURI,
+		"scope":                 "read",
+		"state":                 "xyz",
+		"code_challenge":        util.CodeChallengeS256(verifier),
+		"code_challenge_method": "S256",
+		"approve":               true,
+	})
+	resp.expect(s.t, http.StatusOK, "")
+
+	redirect, err := url.Parse(resp.string(s.t, "redirect_to"))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if redirect.Query().Get("state") != "xyz" {
+		s.t.Fatalf("state was not returned to the client: %s", redirect)
+	}
+	code := redirect.Query().Get("code")
+	if code == "" {
+		s.t.Fatalf("no code in redirect: %s", redirect)
+	}
+	return code
+}
+
+// oauthPost вызывает эндпоинт /oauth/* от имени приложения, как это делает его сервер
+func (s *testServer) oauthPost(path string, client *oauthClient, form url.Values) *response {
+	s.t.Helper()
+
+	if client.Secret == "" {
+		form.Set("client_id", client.ClientID)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if client.Secret != "" {
+		req.SetBasicAuth(client.ClientID, client.Secret)
+	}
+	return s.send(req)
+}
+
+func (s *testServer) exchangeCode(client *oauthClient, code, verifier, redirectURI string) *response {
+	s.t.Helper()
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+	}
+	if redirectURI != "" {
+		form.Set("redirect_uri", redirectURI)
+	}
+	return s.oauthPost("/oauth/token", client, form)
+}
+
+const pkceVerifier = "dBjftJeZ4CVP-mJ92K9QWuSjVRQxtN2W8s4lS9D1xZk"
+
+// expectOAuthError проверяет ответ эндпоинта /oauth/* с ошибкой в формате RFC 6749
+func (r *response) expectOAuthError(t *testing.T, status int, code string) {
+	t.Helper()
+
+	if r.Status != status || r.Body["error"] != code {
+		t.Fatalf("expected %d %s, got %d: %v", status, code, r.Status, r.Body)
+	}
+}
+
+func TestOAuthCodeExchange(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	client := s.registerOAuthClient(alice, true)
+	other := s.registerOAuthClient(alice, true)
+
+	code := s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	s.exchangeCode(&oauthClient{ClientID: client.ClientID, Secret: "wrong"}, code, pkceVerifier, oauthRedirectURI).
+		expectOAuthError(t, http.StatusUnauthorized, "invalid_client")
+	s.exchangeCode(client, code, "", oauthRedirectURI).expectOAuthError(t, http.StatusBadRequest, "invalid_request")
+
+	// Код, выданный другому приложению или с другим code_challenge, не обменивается
+	s.exchangeCode(other, code, pkceVerifier, oauthRedirectURI).expectOAuthError(t, http.StatusBadRequest, "invalid_grant")
+	code = s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	s.exchangeCode(client, code, pkceVerifier+"x", oauthRedirectURI).expectOAuthError(t, http.StatusBadRequest, "invalid_grant")
+
+	code = s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	resp := s.exchangeCode(client, code, pkceVerifier, oauthRedirectURI)
+	resp.expect(t, http.StatusOK, "")
+	if resp.Body["token_type"] != "Bearer" || resp.Body["scope"] != "read" || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Fatalf("unexpected token response: %v %v", resp.Header, resp.Body)
+	}
+
+	// Токен действует только в пределах одобренных областей
+	accessToken := resp.string(t, "access_token")
+	s.do(http.MethodGet, "/api/me", accessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodPost, "/api/posts", accessToken, map[string]string{
+		"title":   "Hello",
+		"content": "From the app",
+	}).expect(t, http.StatusForbidden, "insufficient_scope")
+}
+
+func TestOAuthIntrospectionAndRevocation(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	client := s.registerOAuthClient(alice, true)
+	other := s.registerOAuthClient(alice, false)
+
+	code := s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	tokens := s.exchangeCode(client, code, pkceVerifier, oauthRedirectURI)
+	tokens.expect(t, http.StatusOK, "")
+	accessToken := tokens.string(t, "access_token")
+	refreshToken := tokens.string(t, "refresh_token")
+
+	introspect := func(client *oauthClient, token string) *response {
+		resp := s.oauthPost("/oauth/introspect", client, url.Values{"token": {token}})
+		resp.expect(t, http.StatusOK, "")
+		return resp
+	}
+	revoke := func(client *oauthClient, token string) {
+		s.oauthPost("/oauth/revoke", client, url.Values{"token": {token}}).expect(t, http.StatusOK, "")
+	}
+
+	resp := introspect(client, accessToken)
+	if resp.Body["active"] != true || resp.Body["token_type"] != "Bearer" || resp.Body["scope"] != "read" ||
+		resp.Body["client_id"] != client.ClientID || resp.Body["username"] != alice.Username {
+		t.Fatalf("unexpected introspection of the access token: %v", resp.Body)
+	}
+	resp = introspect(client, refreshToken)
+	if resp.Body["active"] != true || resp.Body["token_type"] != "refresh_token" || resp.Body["username"] != alice.Username {
+		t.Fatalf("unexpected introspection of the refresh token: %v", resp.Body)
+	}
+
+	// Чужие токены приложение не видит и отозвать не может
+	if resp := introspect(other, accessToken); resp.Body["active"] != false {
+		t.Fatalf("token of another client is visible: %v", resp.Body)
+	}
+	revoke(other, accessToken)
+	revoke(other, refreshToken)
+	if resp := introspect(client, accessToken); resp.Body["active"] != true {
+		t.Fatalf("token was revoked by another client: %v", resp.Body)
+	}
+
+	revoke(client, accessToken)
+	if resp := introspect(client, accessToken); resp.Body["active"] != false {
+		t.Fatalf("revoked access token is active: %v", resp.Body)
+	}
+	s.do(http.MethodGet, "/api/me", accessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+
+	revoke(client, refreshToken)
+	if resp := introspect(client, refreshToken); resp.Body["active"] != false {
+		t.Fatalf("revoked refresh token is active: %v", resp.Body)
+	}
+	s.oauthPost("/oauth/token", client, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}).expectOAuthError(t, http.StatusBadRequest, "invalid_grant")
+
+	// Неизвестный токен отзывается без ошибки (RFC 7009)
+	revoke(client, "unknown")
+	s.oauthPost("/oauth/revoke", client, url.Values{}).expectOAuthError(t, http.StatusBadRequest, "invalid_request")
+}
+
+func TestOAuthCodeReuseRevokesIssuedTokens(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	client := s.registerOAuthClient(alice, true)
+
+	code := s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	tokens := s.exchangeCode(client, code, pkceVerifier, oauthRedirectURI)
+	tokens.expect(t, http.StatusOK, "")
+	accessToken := tokens.string(t, "access_token")
+	refreshToken := tokens.string(t, "refresh_token")
+
+	// Обновлённый access-токен выдан по тому же доступу и тоже отзывается
+	refreshed := s.oauthPost("/oauth/token", client, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	refreshed.expect(t, http.StatusOK, "")
+	s.do(http.MethodGet, "/api/me", accessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodGet, "/api/me", refreshed.string(t, "access_token"), nil).expect(t, http.StatusOK, "")
+
+	// Код перехвачен и предъявлен повторно
+	s.exchangeCode(client, code, pkceVerifier, oauthRedirectURI).expectOAuthError(t, http.StatusBadRequest, "invalid_grant")
+
+	s.do(http.MethodGet, "/api/me", accessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.do(http.MethodGet, "/api/me", refreshed.string(t, "access_token"), nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.oauthPost("/oauth/token", client, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshed.string(t, "refresh_token")},
+	}).expect(t, http.StatusBadRequest, "")
+}
+
+func TestOAuthRedirectURIAtTokenEndpoint(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	client := s.registerOAuthClient(alice, false)
+
+	// Адрес возврата передан при авторизации: без него или с другим адресом код не обменять
+	code := s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	s.exchangeCode(client, code, pkceVerifier, "").expectOAuthError(t, http.StatusBadRequest, "invalid_grant")
+	code = s.authorize(alice, client, pkceVerifier, oauthRedirectURI)
+	s.exchangeCode(client, code, pkceVerifier, "https://app.example.com/other").expect(t, http.StatusBadRequest, "")
+
+	// Единственный зарегистрированный адрес можно не передавать ни при авторизации, ни при обмене
+	code = s.authorize(alice, client, pkceVerifier, "")
+	s.exchangeCode(client, code, pkceVerifier, "").expect(t, http.StatusOK, "")
+	code = s.authorize(alice, client, pkceVerifier, "")
+	s.exchangeCode(client, code, pkceVerifier, oauthRedirectURI).expect(t, http.StatusOK, "")
+	code = s.authorize(alice, client, pkceVerifier, "")
+	s.exchangeCode(client, code, pkceVerifier, "https://app.example.com/other").expect(t, http.StatusBadRequest, "")
+}
+
+func TestOAuthConsentErrors(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	client := s.registerOAuthClient(alice, false)
+
+	consent := func(params url.Values) *response {
+		return s.do(http.MethodGet, "/api/oauth/authorize?"+params.Encode(), alice.AccessToken, nil)
+	}
+	valid := func() url.Values {
+		return url.Values{
+			"response_type":         {"code"},
+			"client_id":             {client.ClientID},
+			"state":                 {"xyz"},
+			"code_challenge":        {util.CodeChallengeS256(pkceVerifier)},
+			"code_challenge_method": {"S256"},
+		}
+	}
+
+	consent(valid()).expect(t, http.StatusOK, "")
+	consent(url.Values{"response_type": {"code"}}).expect(t, http.StatusBadRequest, "validation_failed")
+
+	params := valid()
+	params.Set("client_id", "unknown")
+	consent(params).expect(t, http.StatusBadRequest, "oauth_client_not_found")
+
+	// Непроверенный адрес возврата не должен попасть в ответ
+	params = valid()
+	params.Set("redirect_uri", "https://evil.example.com/callback")
+	resp := consent(params)
+	resp.expect(t, http.StatusBadRequest, "redirect_uri_not_registered")
+	if _, ok := resp.Body["redirect_to"]; ok {
+		t.Fatalf("unverified redirect_uri leaked: %v", resp.Body)
+	}
+
+	// Остальные ошибки пользователь видит сам, а приложение получает их через redirect_to
+	params = valid()
+	params.Del("code_challenge")
+	resp = consent(params)
+	resp.expect(t, http.StatusBadRequest, "pkce_required")
+	redirect, err := url.Parse(resp.string(t, "redirect_to"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query := redirect.Query(); query.Get("error") != "invalid_request" || query.Get("state") != "xyz" {
+		t.Fatalf("unexpected redirect: %s", redirect)
+	}
+
+	params = valid()
+	params.Set("scope", "admin")
+	consent(params).expect(t, http.StatusBadRequest, "scope_not_allowed")
+}
@@ -0,0 +1,191 @@
+package router_test
+
+import (
+	"microblog/internal/config"
+	"microblog/internal/oidc/oidctest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const oidcCallbackPath = "/api/auth/oidc/corp/callback"
+
+// newOIDCTestServer поднимает тестового провайдера corp и приложение, настроенное на вход через него
+func newOIDCTestServer(t *testing.T) (*testServer, *oidctest.Server) {
+	t.Helper()
+
+	provider, err := oidctest.NewServer("microblog", "oidc-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(provider.Close)
+
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.OIDC.Providers = []config.OIDCProviderConfig{{
+			Name:         "corp",
+			Issuer:       provider.Issuer(),
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  cfg.Server.PublicURL + oidcCallbackPath,
+			Scopes:       []string{"openid", "email", "profile"},
+		}}
+	})
+	return s, provider
+}
+
+// oidcLogin — начатый вход: cookie с состоянием входа и адрес, на который провайдер вернул пользователя
+type oidcLogin struct {
+	Cookie   *http.Cookie
+	Callback *url.URL
+}
+
+func (s *testServer) startOIDCLogin(provider *oidctest.Server) *oidcLogin {
+	s.t.Helper()
+
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/corp/login", nil))
+	if w.Code != http.StatusFound {
+		s.t.Fatalf("expected redirect to the provider, got %d: %s", w.Code, w.Body.String())
+	}
+
+	login := &oidcLogin{}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "oidc_login" {
+			login.Cookie = cookie
+		}
+	}
+	if login.Cookie == nil {
+		s.t.Fatal("login state cookie was not set")
+	}
+
+	// Провайдер сразу возвращает пользователя с кодом, не показывая страницу входа
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(w.Header().Get("Location"))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		s.t.Fatalf("provider rejected the authorization request: %d", resp.StatusCode)
+	}
+
+	login.Callback, err = url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if login.Callback.Path != oidcCallbackPath {
+		s.t.Fatalf("unexpected callback: %s", login.Callback)
+	}
+	return login
+}
+
+// finishOIDCLogin возвращается в приложение с параметрами query и cookie входа
+func (s *testServer) finishOIDCLogin(login *oidcLogin, query url.Values) *response {
+	s.t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, oidcCallbackPath+"?"+query.Encode(), nil)
+	req.AddCookie(login.Cookie)
+	return s.send(req)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	s, provider := newOIDCTestServer(t)
+	provider.SetUser(oidctest.User{
+		Subject:           "corp-carol",
+		Email:             "carol@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "carol",
+	})
+
+	login := s.startOIDCLogin(provider)
+	resp := s.finishOIDCLogin(login, login.Callback.Query())
+	resp.expect(t, http.StatusOK, "")
+	if user := resp.object(t, "user"); user["username"] != "carol" || user["email_verified"] != true {
+		t.Fatalf("unexpected user: %v", user)
+	}
+	s.do(http.MethodGet, "/api/me", resp.string(t, "access_token"), nil).expect(t, http.StatusOK, "")
+
+	// Код провайдера одноразовый: повторный возврат с ним не открывает вторую сессию
+	s.finishOIDCLogin(login, login.Callback.Query()).expect(t, http.StatusUnauthorized, "provider_error")
+
+	// Повторный вход находит аккаунт по привязке, даже если адрес у провайдера сменился
+	provider.SetUser(oidctest.User{
+		Subject:       "corp-carol",
+		Email:         "carol@corp.example.com",
+		EmailVerified: true,
+	})
+	login = s.startOIDCLogin(provider)
+	resp = s.finishOIDCLogin(login, login.Callback.Query())
+	resp.expect(t, http.StatusOK, "")
+	if user := resp.object(t, "user"); user["username"] != "carol" {
+		t.Fatalf("expected the linked account, got %v", user)
+	}
+}
+
+func TestOIDCStateMismatch(t *testing.T) {
+	s, provider := newOIDCTestServer(t)
+
+	login := s.startOIDCLogin(provider)
+	query := login.Callback.Query()
+	query.Set("state", "forged")
+	s.finishOIDCLogin(login, query).expect(t, http.StatusBadRequest, "login_state_invalid")
+
+	// Без cookie состояние проверить не с чем
+	login = s.startOIDCLogin(provider)
+	req := httptest.NewRequest(http.MethodGet, oidcCallbackPath+"?"+login.Callback.RawQuery, nil)
+	s.send(req).expect(t, http.StatusBadRequest, "login_state_invalid")
+}
+
+func TestOIDCNonceMismatch(t *testing.T) {
+	s, provider := newOIDCTestServer(t)
+
+	// ID-токен выдан для другого входа
+	provider.SetNonce("nonce-of-another-login")
+	login := s.startOIDCLogin(provider)
+	s.finishOIDCLogin(login, login.Callback.Query()).expect(t, http.StatusUnauthorized, "provider_error")
+	s.do(http.MethodGet, "/api/users/oidctest", "", nil).expect(t, http.StatusNotFound, "user_not_found")
+}
+
+func TestOIDCCodeFromAnotherLogin(t *testing.T) {
+	s, provider := newOIDCTestServer(t)
+
+	// Код перехвачен у жертвы и подставлен в собственный вход атакующего: state совпадает
+	// с его cookie, но code_verifier от другого запроса, и провайдер код не обменяет
+	victim := s.startOIDCLogin(provider)
+	attacker := s.startOIDCLogin(provider)
+	query := attacker.Callback.Query()
+	query.Set("code", victim.Callback.Query().Get("code"))
+	s.finishOIDCLogin(attacker, query).expect(t, http.StatusUnauthorized, "provider_error")
+	s.do(http.MethodGet, "/api/users/oidctest", "", nil).expect(t, http.StatusNotFound, "user_not_found")
+}
+
+func TestOIDCLinksAccountWithVerifiedEmail(t *testing.T) {
+	s, provider := newOIDCTestServer(t)
+	alice := s.register("alice")
+
+	provider.SetUser(oidctest.User{
+		Subject:           "corp-alice",
+		Email:             alice.Email,
+		EmailVerified:     true,
+		PreferredUsername: "alice.corp",
+	})
+	login := s.startOIDCLogin(provider)
+	resp := s.finishOIDCLogin(login, login.Callback.Query())
+	resp.expect(t, http.StatusOK, "")
+	if user := resp.object(t, "user"); user["username"] != alice.Username {
+		t.Fatalf("expected the existing account, got %v", user)
+	}
+
+	// Неподтверждённый у провайдера адрес не даёт войти в чужой аккаунт
+	bob := s.register("bob")
+	provider.SetUser(oidctest.User{
+		Subject:       "corp-bob",
+		Email:         bob.Email,
+		EmailVerified: false,
+	})
+	login = s.startOIDCLogin(provider)
+	s.finishOIDCLogin(login, login.Callback.Query()).expect(t, http.StatusConflict, "account_conflict")
+}
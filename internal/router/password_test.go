@@ -0,0 +1,60 @@
+package router_test
+
+import (
+	"microblog/internal/config"
+	"net/http"
+	"testing"
+)
+
+func TestPasswordReset(t *testing.T) {
+	s := newTestServerWith(t, func(cfg *config.Config) {
+		cfg.Lockout.MaxFailures = 3
+	})
+	alice := s.register("alice")
+
+	// Ответ не выдаёт, зарегистрирован ли адрес
+	s.do(http.MethodPost, "/api/auth/forgot-password", "", map[string]string{
+		"email": "nobody@example.com",
+	}).expect(t, http.StatusOK, "")
+	s.do(http.MethodPost, "/api/auth/forgot-password", "", map[string]string{
+		"email": alice.Email,
+	}).expect(t, http.StatusOK, "")
+	token := s.mail.awaitToken(t, alice.Email, "Reset your password")
+
+	// Подбор пароля заблокировал аккаунт; сброс по ссылке из письма снимает блокировку
+	for i := 0; i < 3; i++ {
+		s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+			"email":    alice.Email,
+			"password": "wrong",
+		})
+	}
+	s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    alice.Email,
+		"password": alice.Password,
+	}).expect(t, http.StatusLocked, "account_locked")
+
+	s.do(http.MethodPost, "/api/auth/reset-password", "", map[string]string{
+		"token":    token,
+		"password": "new-secret",
+	}).expect(t, http.StatusOK, "")
+
+	// Выданные до сброса токены и сессии больше не действуют
+	s.do(http.MethodGet, "/api/me", alice.AccessToken, nil).expect(t, http.StatusUnauthorized, "token_revoked")
+	s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{
+		"refresh_token": alice.RefreshToken,
+	}).expect(t, http.StatusUnauthorized, "")
+
+	s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    alice.Email,
+		"password": alice.Password,
+	}).expect(t, http.StatusUnauthorized, "invalid_credentials")
+	alice.Password = "new-secret"
+	s.login(alice)
+
+	// Ссылка одноразовая
+	s.do(http.MethodPost, "/api/auth/reset-password", "", map[string]string{
+		"token":    token,
+		"password": "another-secret",
+	}).expect(t, http.StatusBadRequest, "invalid_or_expired_token")
+	s.login(alice)
+}
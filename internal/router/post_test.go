@@ -0,0 +1,160 @@
+package router_test
+
+import (
+	"fmt"
+	"microblog/internal/model"
+	"net/http"
+	"testing"
+)
+
+func TestCreatePostRequiresVerifiedEmail(t *testing.T) {
+	s := newTestServer(t)
+
+	bob := &account{Username: "bob", Email: "bob@example.com", Password: "secret-bob"}
+	s.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"username": bob.Username,
+		"email":    bob.Email,
+		"password": bob.Password,
+	}).expect(t, http.StatusCreated, "")
+	s.login(bob)
+
+	s.do(http.MethodPost, "/api/posts", bob.AccessToken, map[string]string{
+		"title":   "Hello",
+		"content": "World",
+	}).expect(t, http.StatusForbidden, "email_not_verified")
+}
+
+func TestPostCRUD(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	s.do(http.MethodPost, "/api/posts", "", map[string]string{
+		"title":   "Hello",
+		"content": "World",
+	}).expect(t, http.StatusUnauthorized, "")
+	s.do(http.MethodPost, "/api/posts", alice.AccessToken, map[string]string{
+		"title": "Hello",
+	}).expect(t, http.StatusBadRequest, "validation_failed")
+
+	postID := s.createPost(alice, "Hello")
+	path := fmt.Sprintf("/api/posts/%d", postID)
+
+	resp := s.do(http.MethodGet, path, "", nil)
+	resp.expect(t, http.StatusOK, "")
+	post := resp.object(t, "post")
+	if post["title"] != "Hello" {
+		t.Fatalf("unexpected title: %v", post["title"])
+	}
+	if author, _ := post["author"].(map[string]interface{}); author["username"] != alice.Username {
+		t.Fatalf("unexpected author: %v", post["author"])
+	}
+
+	resp = s.do(http.MethodPut, path, alice.AccessToken, map[string]string{
+		"title":   "Hello again",
+		"content": "Updated",
+	})
+	resp.expect(t, http.StatusOK, "")
+	if title := resp.object(t, "post")["title"]; title != "Hello again" {
+		t.Fatalf("post was not updated: %v", title)
+	}
+
+	resp = s.do(http.MethodGet, "/api/posts/my", alice.AccessToken, nil)
+	resp.expect(t, http.StatusOK, "")
+	if posts := resp.list(t, "posts"); len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+
+	s.do(http.MethodDelete, path, alice.AccessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodGet, path, "", nil).expect(t, http.StatusNotFound, "post_not_found")
+	s.do(http.MethodDelete, path, alice.AccessToken, nil).expect(t, http.StatusNotFound, "post_not_found")
+}
+
+func TestPostInvalidID(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	s.do(http.MethodGet, "/api/posts/abc", "", nil).expect(t, http.StatusBadRequest, "invalid_id")
+	s.do(http.MethodPut, "/api/posts/abc", alice.AccessToken, map[string]string{
+		"title":   "Hello",
+		"content": "World",
+	}).expect(t, http.StatusBadRequest, "invalid_id")
+	s.do(http.MethodDelete, "/api/posts/abc", alice.AccessToken, nil).expect(t, http.StatusBadRequest, "invalid_id")
+}
+
+func TestPostOwnership(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+	bob := s.register("bob")
+
+	postID := s.createPost(alice, "Hello")
+	path := fmt.Sprintf("/api/posts/%d", postID)
+
+	s.do(http.MethodPut, path, bob.AccessToken, map[string]string{
+		"title":   "Hijacked",
+		"content": "Hijacked",
+	}).expect(t, http.StatusForbidden, "not_owner")
+	s.do(http.MethodDelete, path, bob.AccessToken, nil).expect(t, http.StatusForbidden, "not_owner")
+
+	// Модератор управляет только комментариями
+	s.setRole(bob, model.RoleModerator)
+	s.do(http.MethodDelete, path, bob.AccessToken, nil).expect(t, http.StatusForbidden, "not_owner")
+
+	s.setRole(bob, model.RoleAdmin)
+
+	// Персональный токен администратора действует без полномочий его роли
+	resp := s.do(http.MethodPost, "/api/tokens", bob.AccessToken, map[string]interface{}{
+		"name":   "cli",
+		"scopes": []string{model.ScopePostsWrite},
+	})
+	resp.expect(t, http.StatusCreated, "")
+	s.do(http.MethodDelete, path, resp.string(t, "token"), nil).expect(t, http.StatusForbidden, "not_owner")
+
+	s.do(http.MethodDelete, path, bob.AccessToken, nil).expect(t, http.StatusOK, "")
+	s.do(http.MethodGet, path, "", nil).expect(t, http.StatusNotFound, "post_not_found")
+}
+
+func TestPostPagination(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	ids := make([]int64, 12)
+	for i := range ids {
+		ids[i] = s.createPost(alice, fmt.Sprintf("Post %d", i+1))
+	}
+
+	cases := []struct {
+		query string
+		count int
+		first int64
+	}{
+		{"", 10, ids[11]},
+		{"?limit=5", 5, ids[11]},
+		{"?limit=5&offset=5", 5, ids[6]},
+		{"?offset=10", 2, ids[1]},
+		{"?offset=12", 0, 0},
+		{"?limit=100", 12, ids[11]},
+		{"?limit=101", 10, ids[11]},
+		{"?limit=0", 10, ids[11]},
+		{"?limit=-1", 10, ids[11]},
+		{"?limit=abc", 10, ids[11]},
+		{"?offset=-5", 10, ids[11]},
+		{"?offset=abc", 10, ids[11]},
+	}
+
+	for _, tc := range cases {
+		for _, path := range []string{"/api/posts", "/api/users/alice/posts"} {
+			resp := s.do(http.MethodGet, path+tc.query, "", nil)
+			resp.expect(t, http.StatusOK, "")
+
+			posts := resp.list(t, "posts")
+			if len(posts) != tc.count {
+				t.Fatalf("%s%s: expected %d posts, got %d", path, tc.query, tc.count, len(posts))
+			}
+			if tc.count > 0 {
+				if first := id(t, posts[0].(map[string]interface{})); first != tc.first {
+					t.Fatalf("%s%s: expected newest post %d first, got %d", path, tc.query, tc.first, first)
+				}
+			}
+		}
+	}
+}
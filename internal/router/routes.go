@@ -1,48 +1,152 @@
 package router
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"microblog/internal/apierr"
 	"microblog/internal/handler"
 	"microblog/internal/middleware"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"net/http"
 )
 
-func Routers() *gin.Engine {
+// Routers собирает маршруты API. IP клиента берётся из X-Forwarded-For только от trustedProxies,
+// иначе из адреса соединения.
+func Routers(h *handler.Handlers, tokens repository.PersonalAccessTokenRepository, revoker *revocation.Revoker,
+	trustedProxies []string) (*gin.Engine, error) {
 	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	requireAuth := middleware.AuthMiddleware(tokens, revoker)
+	r.Use(middleware.Locale())
+
+	r.NoRoute(func(c *gin.Context) {
+		apierr.Respond(c, http.StatusNotFound, apierr.CodeNotFound)
+	})
 
 	r.GET("/ping", handler.Ping)
 
+	// Проверки для оркестратора: процесс жив и экземпляр готов принимать трафик
+	r.GET("/healthz", h.Health.Healthz)
+	r.GET("/readyz", h.Health.Readyz)
+
+	// Публичные ключи для проверки access-токенов другими сервисами
+	r.GET("/.well-known/jwks.json", handler.JWKS)
+
 	auth := r.Group("/api/auth")
 	{
-		auth.POST("/register", handler.Register)
-		auth.POST("/login", handler.Login)
-		auth.POST("/refresh", handler.RefreshToken)
-		auth.POST("/logout", middleware.AuthMiddleware(), handler.Logout)
+		auth.POST("/register", h.Auth.Register)
+		auth.POST("/login", h.Auth.Login)
+		auth.POST("/login/mfa", h.Auth.LoginMFA)
+		auth.POST("/refresh", h.Auth.RefreshToken)
+		auth.POST("/logout", requireAuth, middleware.RequireSession(), h.Auth.Logout)
+		auth.POST("/verify-email", h.Auth.VerifyEmail)
+		auth.POST("/forgot-password", h.Auth.ForgotPassword)
+		auth.POST("/reset-password", h.Auth.ResetPassword)
+		auth.POST("/resend-verification", requireAuth, h.Auth.ResendVerificationEmail)
+
+		// Вход через внешних OpenID Connect провайдеров
+		auth.GET("/oidc/:provider/login", h.Auth.OIDCLogin)       // GET /api/auth/oidc/corp/login
+		auth.GET("/oidc/:provider/callback", h.Auth.OIDCCallback) // GET /api/auth/oidc/corp/callback
 	}
 
 	// Публичные маршруты для постов
 	posts := r.Group("/api/posts")
 	{
-		posts.GET("", handler.GetAllPosts)                           // GET /api/posts
-		posts.GET("/:id", handler.GetPost)                           // GET /api/posts/1
-		posts.GET("/:id/with-comments", handler.GetPostWithComments) // GET /api/posts/1/with-comments
-		posts.GET("/:id/comments", handler.GetCommentsByPost)        // GET /api/posts/1/comments
+		posts.GET("", h.Posts.GetAllPosts)                              // GET /api/posts
+		posts.GET("/:id", h.Posts.GetPost)                              // GET /api/posts/1
+		posts.GET("/:id/with-comments", h.Comments.GetPostWithComments) // GET /api/posts/1/with-comments
+		posts.GET("/:id/comments", h.Comments.GetCommentsByPost)        // GET /api/posts/1/comments
+	}
+
+	// Публичные профили пользователей
+	users := r.Group("/api/users")
+	{
+		users.GET("/:username", h.Users.GetUserProfile)     // GET /api/users/bob
+		users.GET("/:username/posts", h.Users.GetUserPosts) // GET /api/users/bob/posts
 	}
 
-	// Защищенные маршруты
+	// Скачивание архива с данными по ссылке из письма
+	r.GET("/api/exports/download", h.Auth.DownloadDataExport) // GET /api/exports/download?token=...
+
+	// Защищенные маршруты; доступны и по персональным токенам с нужной областью
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(requireAuth)
 	{
+		canRead := middleware.RequireScope(model.ScopeRead)
+		canWritePosts := middleware.RequireScope(model.ScopePostsWrite)
+		canWriteComments := middleware.RequireScope(model.ScopeCommentsWrite)
+
+		api.GET("/me", canRead, h.Users.GetMe) // GET /api/me
+
 		// Маршруты для постов (требуют авторизации)
-		api.POST("/posts", handler.CreatePost)       // POST /api/posts
-		api.GET("/posts/my", handler.GetMyPosts)     // GET /api/posts/my
-		api.PUT("/posts/:id", handler.UpdatePost)    // PUT /api/posts/1
-		api.DELETE("/posts/:id", handler.DeletePost) // DELETE /api/posts/1
+		api.POST("/posts", canWritePosts, h.Posts.CreatePost)       // POST /api/posts
+		api.GET("/posts/my", canRead, h.Posts.GetMyPosts)           // GET /api/posts/my
+		api.PUT("/posts/:id", canWritePosts, h.Posts.UpdatePost)    // PUT /api/posts/1
+		api.DELETE("/posts/:id", canWritePosts, h.Posts.DeletePost) // DELETE /api/posts/1
 
 		// Маршруты для комментариев (требуют авторизации)
-		api.POST("/posts/:id/comments", handler.CreateComment) // POST /api/posts/1/comments
-		api.PUT("/comments/:id", handler.UpdateComment)        // PUT /api/comments/1
-		api.DELETE("/comments/:id", handler.DeleteComment)     // DELETE /api/comments/1
+		api.POST("/posts/:id/comments", canWriteComments, h.Comments.CreateComment) // POST /api/posts/1/comments
+		api.PUT("/comments/:id", canWriteComments, h.Comments.UpdateComment)        // PUT /api/comments/1
+		api.DELETE("/comments/:id", canWriteComments, h.Comments.DeleteComment)     // DELETE /api/comments/1
+	}
+
+	// Управление аккаунтом только из сессии пользователя, не по токенам доступа
+	account := r.Group("/api")
+	account.Use(requireAuth, middleware.RequireSession())
+	{
+		account.PATCH("/me", h.Users.UpdateProfile)                       // PATCH /api/me
+		account.DELETE("/me", h.Auth.DeleteAccount)                       // DELETE /api/me
+		account.POST("/me/deletion/cancel", h.Auth.CancelAccountDeletion) // POST /api/me/deletion/cancel
+		account.PUT("/me/password", h.Auth.ChangePassword)                // PUT /api/me/password
+		account.POST("/me/email", h.Auth.RequestEmailChange)              // POST /api/me/email
+		account.POST("/me/email/confirm", h.Auth.ConfirmEmailChange)      // POST /api/me/email/confirm
+		account.POST("/me/export", h.Auth.RequestDataExport)              // POST /api/me/export
+		account.GET("/me/export", h.Auth.GetDataExport)                   // GET /api/me/export
+
+		// Двухфакторная аутентификация (TOTP)
+		account.GET("/me/2fa", h.Auth.GetTOTPStatus)                           // GET /api/me/2fa
+		account.POST("/me/2fa/setup", h.Auth.SetupTOTP)                        // POST /api/me/2fa/setup
+		account.POST("/me/2fa/confirm", h.Auth.ConfirmTOTP)                    // POST /api/me/2fa/confirm
+		account.POST("/me/2fa/disable", h.Auth.DisableTOTP)                    // POST /api/me/2fa/disable
+		account.POST("/me/2fa/recovery-codes", h.Auth.RegenerateRecoveryCodes) // POST /api/me/2fa/recovery-codes
+
+		// Сессии пользователя на разных устройствах
+		account.GET("/sessions", h.Auth.GetSessions)            // GET /api/sessions
+		account.DELETE("/sessions", h.Auth.RevokeOtherSessions) // DELETE /api/sessions
+		account.DELETE("/sessions/:id", h.Auth.RevokeSession)   // DELETE /api/sessions/1
+
+		// Персональные токены доступа для ботов и скриптов
+		account.GET("/tokens", h.Auth.GetPersonalAccessTokens)          // GET /api/tokens
+		account.POST("/tokens", h.Auth.CreatePersonalAccessToken)       // POST /api/tokens
+		account.DELETE("/tokens/:id", h.Auth.DeletePersonalAccessToken) // DELETE /api/tokens/1
+
+		// Сторонние приложения: регистрация и экран согласия
+		account.GET("/oauth/clients", h.OAuth.GetOAuthClients)          // GET /api/oauth/clients
+		account.POST("/oauth/clients", h.OAuth.CreateOAuthClient)       // POST /api/oauth/clients
+		account.DELETE("/oauth/clients/:id", h.OAuth.DeleteOAuthClient) // DELETE /api/oauth/clients/1
+		account.GET("/oauth/authorize", h.OAuth.GetOAuthConsent)        // GET /api/oauth/authorize?client_id=...
+		account.POST("/oauth/authorize", h.OAuth.ApproveOAuthConsent)   // POST /api/oauth/authorize
+	}
+
+	// Эндпоинты OAuth 2.0 для приложений; аутентификация по client_id и секрету
+	oauth := r.Group("/oauth")
+	{
+		oauth.POST("/token", h.OAuth.OAuthToken)           // POST /oauth/token
+		oauth.POST("/introspect", h.OAuth.OAuthIntrospect) // POST /oauth/introspect
+		oauth.POST("/revoke", h.OAuth.OAuthRevoke)         // POST /oauth/revoke
+	}
+
+	// Администрирование
+	admin := r.Group("/api/admin")
+	admin.Use(requireAuth, middleware.RequireSession(), middleware.RequireRole(model.RoleAdmin))
+	{
+		admin.POST("/users/:username/unlock", h.Auth.UnlockUser) // POST /api/admin/users/bob/unlock
+		admin.PUT("/users/:username/role", h.Auth.SetUserRole)   // PUT /api/admin/users/bob/role
 	}
 
-	return r
+	return r, nil
 }
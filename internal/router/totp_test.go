@@ -0,0 +1,117 @@
+package router_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpStep — текущий 30-секундный шаг TOTP
+func totpStep() int64 {
+	return time.Now().Unix() / 30
+}
+
+// totpCode считает код по RFC 6238 так же, как приложение-аутентификатор
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// loginMFA входит с паролем и возвращает токен проверки второго фактора
+func (s *testServer) loginMFA(acc *account) string {
+	s.t.Helper()
+
+	resp := s.do(http.MethodPost, "/api/auth/login", "", map[string]string{
+		"email":    acc.Email,
+		"password": acc.Password,
+	})
+	resp.expect(s.t, http.StatusOK, "")
+	if resp.Body["mfa_required"] != true || resp.Body["access_token"] != nil {
+		s.t.Fatalf("expected an MFA challenge, got %v", resp.Body)
+	}
+	return resp.string(s.t, "mfa_token")
+}
+
+func TestTOTPLogin(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.register("alice")
+
+	s.do(http.MethodPost, "/api/me/2fa/confirm", alice.AccessToken, map[string]string{
+		"code": "123456",
+	}).expect(t, http.StatusBadRequest, "mfa_setup_not_started")
+
+	setup := s.do(http.MethodPost, "/api/me/2fa/setup", alice.AccessToken, nil)
+	setup.expect(t, http.StatusOK, "")
+	secret := setup.string(t, "secret")
+
+	step := totpStep()
+	s.do(http.MethodPost, "/api/me/2fa/confirm", alice.AccessToken, map[string]string{
+		"code": "12345",
+	}).expect(t, http.StatusBadRequest, "invalid_mfa_code")
+	confirm := s.do(http.MethodPost, "/api/me/2fa/confirm", alice.AccessToken, map[string]string{
+		"code": totpCode(t, secret, step),
+	})
+	confirm.expect(t, http.StatusOK, "")
+	recoveryCodes := confirm.list(t, "recovery_codes")
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("expected 10 recovery codes, got %d", len(recoveryCodes))
+	}
+
+	// Пароля недостаточно: вход завершается только после второго фактора
+	mfaToken := s.loginMFA(alice)
+	loginMFA := func(token string, body map[string]string) *response {
+		body["mfa_token"] = token
+		return s.do(http.MethodPost, "/api/auth/login/mfa", "", body)
+	}
+
+	// Код, уже принятый при подключении, повторно не подходит
+	loginMFA(mfaToken, map[string]string{
+		"code": totpCode(t, secret, step),
+	}).expect(t, http.StatusUnauthorized, "invalid_mfa_code")
+
+	resp := loginMFA(mfaToken, map[string]string{"code": totpCode(t, secret, step+1)})
+	resp.expect(t, http.StatusOK, "")
+	s.do(http.MethodGet, "/api/me", resp.string(t, "access_token"), nil).expect(t, http.StatusOK, "")
+
+	// Токен проверки одноразовый
+	loginMFA(mfaToken, map[string]string{
+		"recovery_code": recoveryCodes[0].(string),
+	}).expect(t, http.StatusUnauthorized, "invalid_or_expired_token")
+
+	// Код восстановления действует один раз; регистр и дефис при вводе не важны
+	resp = loginMFA(s.loginMFA(alice), map[string]string{
+		"recovery_code": recoveryCodes[0].(string),
+	})
+	resp.expect(t, http.StatusOK, "")
+	loginMFA(s.loginMFA(alice), map[string]string{
+		"recovery_code": recoveryCodes[0].(string),
+	}).expect(t, http.StatusUnauthorized, "invalid_mfa_code")
+	loginMFA(s.loginMFA(alice), map[string]string{
+		"recovery_code": strings.ToUpper(strings.ReplaceAll(recoveryCodes[1].(string), "-", "")),
+	}).expect(t, http.StatusOK, "")
+
+	status := s.do(http.MethodGet, "/api/me/2fa", resp.string(t, "access_token"), nil)
+	status.expect(t, http.StatusOK, "")
+	if status.Body["enabled"] != true || status.Body["recovery_codes_remaining"] != float64(8) {
+		t.Fatalf("unexpected 2FA status: %v", status.Body)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"microblog/internal/lockout"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"microblog/internal/util"
+	"time"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	emailChangeTTL   = 24 * time.Hour
+)
+
+// AccountService меняет учётные данные пользователя и назначает удаление аккаунта
+type AccountService struct {
+	users    repository.UserRepository
+	resets   repository.PasswordResetRepository
+	sessions *SessionService
+	revoker  *revocation.Revoker
+	limiter  *lockout.Limiter
+	// deletionGracePeriod — сколько удаление аккаунта можно отменить
+	deletionGracePeriod time.Duration
+}
+
+func NewAccountService(users repository.UserRepository, resets repository.PasswordResetRepository, sessions *SessionService,
+	revoker *revocation.Revoker, limiter *lockout.Limiter, deletionGracePeriod time.Duration) *AccountService {
+	return &AccountService{
+		users:               users,
+		resets:              resets,
+		sessions:            sessions,
+		revoker:             revoker,
+		limiter:             limiter,
+		deletionGracePeriod: deletionGracePeriod,
+	}
+}
+
+// VerifyPassword подтверждает действие, опасное для аккаунта, текущим паролем
+func (s *AccountService) VerifyPassword(user *model.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// ChangePassword меняет пароль и завершает остальные сессии; возвращает новый access-токен текущей сессии
+func (s *AccountService) ChangePassword(user *model.User, currentPassword, newPassword string, sessionID int64) (string, error) {
+	if err := s.VerifyPassword(user, currentPassword); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.users.UpdateUserPassword(user.ID, string(hashedPassword)); err != nil {
+		return "", err
+	}
+
+	return s.sessions.InvalidateOthers(user, sessionID)
+}
+
+// RequestPasswordReset выдаёт одноразовый токен сброса для зарегистрированного адреса
+func (s *AccountService) RequestPasswordReset(email string) (*model.User, string, error) {
+	user, err := s.users.GetUserByEmail(email)
+	if err != nil {
+		return nil, "", notFound(err, ErrUserNotFound)
+	}
+
+	token, err := util.GenerateRandomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.resets.CreatePasswordResetToken(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, token, nil
+}
+
+// ResetPassword меняет пароль по токену из письма и возвращает владельца аккаунта
+func (s *AccountService) ResetPassword(token, password string) (*model.User, error) {
+	reset, err := s.resets.GetPasswordResetTokenByHash(util.HashToken(token))
+	if err != nil {
+		return nil, notFound(err, ErrInvalidToken)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resets.ResetUserPassword(reset, string(hashedPassword)); err != nil {
+		if errors.Is(err, repository.ErrResetTokenUsed) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	// Сессии удалены вместе со сменой пароля, осталось погасить выданные access-токены
+	if err := s.revoker.RevokeUserTokens(reset.User.Username); err != nil {
+		log.Printf("Failed to revoke access tokens for user %d: %v", reset.UserID, err)
+	}
+
+	// Владелец подтвердил доступ к почте, блокировку после подбора можно снять
+	if err := s.limiter.Unlock(reset.User.Email); err != nil {
+		log.Printf("Failed to unlock user %d: %v", reset.UserID, err)
+	}
+
+	return &reset.User, nil
+}
+
+// RequestEmailChange запоминает новый адрес и выдаёт токен для его подтверждения
+func (s *AccountService) RequestEmailChange(user *model.User, newEmail, password string) (string, error) {
+	if err := s.VerifyPassword(user, password); err != nil {
+		return "", err
+	}
+
+	if newEmail == user.Email {
+		return "", ErrEmailUnchanged
+	}
+
+	if existing, _ := s.users.GetUserByEmail(newEmail); existing != nil {
+		return "", ErrEmailTaken
+	}
+
+	if err := s.users.SetUserPendingEmail(user.ID, newEmail); err != nil {
+		return "", err
+	}
+
+	return util.GenerateActionToken(util.PurposeEmailChange, user.Username, newEmail, "", emailChangeTTL)
+}
+
+// ConfirmEmailChange переносит аккаунт на подтверждённый адрес и завершает остальные сессии.
+// Возвращает прежний адрес и новый access-токен текущей сессии.
+func (s *AccountService) ConfirmEmailChange(user *model.User, token string, sessionID int64) (string, string, error) {
+	// Действителен только токен для последнего запрошенного адреса
+	claims, err := util.ValidateActionToken(token, util.PurposeEmailChange)
+	if err != nil || claims.Subject != user.Username || claims.Email != user.PendingEmail {
+		return "", "", ErrInvalidToken
+	}
+
+	if existing, _ := s.users.GetUserByEmail(claims.Email); existing != nil {
+		return "", "", ErrEmailTaken
+	}
+
+	if err := s.users.ChangeUserEmail(user.ID, claims.Email); err != nil {
+		return "", "", err
+	}
+
+	oldEmail := user.Email
+	user.Email = claims.Email
+	user.PendingEmail = ""
+
+	accessToken, err := s.sessions.InvalidateOthers(user, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return oldEmail, accessToken, nil
+}
+
+// ScheduleDeletion назначает удаление аккаунта по истечении срока отмены
+func (s *AccountService) ScheduleDeletion(user *model.User) (time.Time, error) {
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, ErrDeletionScheduled
+	}
+
+	scheduledAt := time.Now().Add(s.deletionGracePeriod)
+	if err := s.users.ScheduleUserDeletion(user.ID, &scheduledAt); err != nil {
+		return time.Time{}, err
+	}
+	return scheduledAt, nil
+}
+
+func (s *AccountService) CancelDeletion(user *model.User) error {
+	if user.DeletionScheduledAt == nil {
+		return ErrDeletionNotScheduled
+	}
+	return s.users.ScheduleUserDeletion(user.ID, nil)
+}
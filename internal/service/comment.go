@@ -0,0 +1,74 @@
+package service
+
+import (
+	"microblog/internal/model"
+	"microblog/internal/policy"
+	"microblog/internal/repository"
+)
+
+// CommentService отвечает за правила комментирования
+type CommentService struct {
+	comments repository.CommentRepository
+	posts    *PostService
+}
+
+func NewCommentService(comments repository.CommentRepository, posts *PostService) *CommentService {
+	return &CommentService{comments: comments, posts: posts}
+}
+
+// Create добавляет комментарий к существующему посту; комментировать могут только подтвердившие email
+func (s *CommentService) Create(author *model.User, postID int64, content string) (*model.Comment, error) {
+	if !author.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	if _, err := s.posts.Get(postID); err != nil {
+		return nil, err
+	}
+
+	return s.comments.CreateComment(&model.Comment{
+		Content:  content,
+		PostID:   postID,
+		AuthorID: author.ID,
+	})
+}
+
+func (s *CommentService) ListByPost(postID int64, limit, offset int) ([]model.Comment, error) {
+	if _, err := s.posts.Get(postID); err != nil {
+		return nil, err
+	}
+	return s.comments.GetCommentsByPostID(postID, limit, offset)
+}
+
+func (s *CommentService) Get(id int64) (*model.Comment, error) {
+	comment, err := s.comments.GetCommentByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrCommentNotFound)
+	}
+	return comment, nil
+}
+
+func (s *CommentService) Update(user *model.User, id int64, content string) (*model.Comment, error) {
+	comment, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanUpdateComment(user, comment) {
+		return nil, ErrNotOwner
+	}
+
+	return s.comments.UpdateComment(id, &model.Comment{
+		Content: content,
+	})
+}
+
+func (s *CommentService) Delete(user *model.User, id int64) error {
+	comment, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeleteComment(user, comment) {
+		return ErrNotOwner
+	}
+
+	return s.comments.DeleteComment(id)
+}
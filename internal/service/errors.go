@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"gorm.io/gorm"
+)
+
+// Ошибки бизнес-правил; обработчики переводят их в коды ответов API
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrPostNotFound     = errors.New("post not found")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrNotOwner         = errors.New("not allowed to modify content of another user")
+	ErrEmailNotVerified = errors.New("email is not verified")
+
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrIncorrectPassword    = errors.New("incorrect password")
+	ErrEmailTaken           = errors.New("email is already taken")
+	ErrEmailUnchanged       = errors.New("new email matches the current one")
+	ErrDeletionScheduled    = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
+)
+
+// notFound подменяет отсутствие записи ошибкой сервиса, остальные ошибки возвращает как есть
+func notFound(err, replacement error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return replacement
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"microblog/internal/model"
+	"microblog/internal/policy"
+	"microblog/internal/repository"
+)
+
+// PostService отвечает за правила публикации и изменения постов
+type PostService struct {
+	posts repository.PostRepository
+}
+
+func NewPostService(posts repository.PostRepository) *PostService {
+	return &PostService{posts: posts}
+}
+
+// Create публикует пост от имени автора; писать могут только подтвердившие email
+func (s *PostService) Create(author *model.User, title, content string) (*model.Post, error) {
+	if !author.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.posts.CreatePost(&model.Post{
+		Title:    title,
+		Content:  content,
+		AuthorID: author.ID,
+	})
+}
+
+func (s *PostService) Get(id int64) (*model.Post, error) {
+	post, err := s.posts.GetPostByID(id)
+	if err != nil {
+		return nil, notFound(err, ErrPostNotFound)
+	}
+	return post, nil
+}
+
+func (s *PostService) GetWithComments(id int64, commentLimit, commentOffset int) (*model.Post, error) {
+	post, err := s.posts.GetPostByIDWithComments(id, commentLimit, commentOffset)
+	if err != nil {
+		return nil, notFound(err, ErrPostNotFound)
+	}
+	return post, nil
+}
+
+func (s *PostService) List(limit, offset int) ([]model.Post, error) {
+	return s.posts.GetAllPosts(limit, offset)
+}
+
+func (s *PostService) ListByAuthor(authorID int64, limit, offset int) ([]model.Post, error) {
+	return s.posts.GetPostsByAuthor(authorID, limit, offset)
+}
+
+// Update меняет пост, если пользователю это разрешено политикой доступа
+func (s *PostService) Update(user *model.User, id int64, title, content string) (*model.Post, error) {
+	post, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanUpdatePost(user, post) {
+		return nil, ErrNotOwner
+	}
+
+	return s.posts.UpdatePost(id, &model.Post{
+		Title:   title,
+		Content: content,
+	})
+}
+
+func (s *PostService) Delete(user *model.User, id int64) error {
+	post, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeletePost(user, post) {
+		return ErrNotOwner
+	}
+
+	return s.posts.DeletePost(id)
+}
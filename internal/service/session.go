@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"microblog/internal/model"
+	"microblog/internal/repository"
+	"microblog/internal/revocation"
+	"microblog/internal/util"
+	"time"
+)
+
+// Device описывает устройство, с которого открывается сессия
+type Device struct {
+	Name      string
+	UserAgent string
+	IP        string
+}
+
+// RefreshReuseError возвращается, когда предъявлен уже использованный refresh-токен;
+// к этому моменту всё семейство токенов уже отозвано
+type RefreshReuseError struct {
+	Session           *model.Session
+	PresentedSequence int
+}
+
+func (e *RefreshReuseError) Error() string {
+	return fmt.Sprintf("session %d: presented sequence %d, current sequence %d",
+		e.Session.ID, e.PresentedSequence, e.Session.Sequence)
+}
+
+// SessionService ведёт сессии устройств и выданные для них токены
+type SessionService struct {
+	sessions repository.SessionRepository
+	revoker  *revocation.Revoker
+}
+
+func NewSessionService(sessions repository.SessionRepository, revoker *revocation.Revoker) *SessionService {
+	return &SessionService{sessions: sessions, revoker: revoker}
+}
+
+// Start создаёт сессию устройства и выдаёт для неё пару токенов
+func (s *SessionService) Start(user *model.User, device Device) (*model.Session, string, string, error) {
+	familyID, err := util.GenerateRandomString(16)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	refreshToken, err := util.GenerateRefreshToken(user.Username, familyID, 0)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	accessTokenID, err := util.NewTokenID()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	now := time.Now()
+	session, err := s.sessions.CreateSession(&model.Session{
+		UserID:        user.ID,
+		FamilyID:      familyID,
+		AccessTokenID: accessTokenID,
+		DeviceName:    device.Name,
+		UserAgent:     device.UserAgent,
+		IP:            device.IP,
+		LastUsedAt:    now,
+		ExpiresAt:     now.Add(util.RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	accessToken, err := util.GenerateToken(user, session.ID, accessTokenID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return session, accessToken, refreshToken, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару. Повторное предъявление токена
+// завершает сессию и возвращает *RefreshReuseError.
+func (s *SessionService) Refresh(refreshToken string) (string, string, error) {
+	claims, err := util.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", ErrInvalidToken
+	}
+
+	session, err := s.sessions.GetSessionByFamilyID(claims.FamilyID)
+	if err != nil || session.User.Username != claims.Username {
+		return "", "", ErrInvalidToken
+	}
+
+	if claims.Sequence != session.Sequence {
+		return "", "", s.revokeFamily(session, claims.Sequence)
+	}
+
+	accessTokenID, err := util.NewTokenID()
+	if err != nil {
+		return "", "", err
+	}
+
+	newAccessToken, err := util.GenerateToken(&session.User, session.ID, accessTokenID)
+	if err != nil {
+		return "", "", err
+	}
+
+	newRefreshToken, err := util.GenerateRefreshToken(session.User.Username, session.FamilyID, session.Sequence+1)
+	if err != nil {
+		return "", "", err
+	}
+
+	expiresAt := time.Now().Add(util.RefreshTokenTTL)
+	advanced, err := s.sessions.AdvanceSessionSequence(session.ID, session.Sequence, accessTokenID, expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Параллельный запрос успел использовать этот же токен
+	if !advanced {
+		return "", "", s.revokeFamily(session, claims.Sequence)
+	}
+
+	return newAccessToken, newRefreshToken, nil
+}
+
+func (s *SessionService) revokeFamily(session *model.Session, presentedSequence int) error {
+	if err := s.End(session); err != nil {
+		return err
+	}
+	return &RefreshReuseError{Session: session, PresentedSequence: presentedSequence}
+}
+
+// Logout завершает текущую сессию и отзывает access-токен запроса
+func (s *SessionService) Logout(sessionID int64, tokenID string, expiresAt time.Time) error {
+	if err := s.sessions.DeleteSession(sessionID); err != nil {
+		return err
+	}
+	return s.revoker.Revoke(tokenID, expiresAt)
+}
+
+func (s *SessionService) List(userID int64) ([]model.Session, error) {
+	return s.sessions.GetActiveSessionsByUserID(userID)
+}
+
+// Revoke завершает одну из сессий пользователя
+func (s *SessionService) Revoke(userID, sessionID int64) error {
+	session, err := s.sessions.GetSessionByID(sessionID)
+	if err != nil {
+		return notFound(err, ErrSessionNotFound)
+	}
+
+	if session.UserID != userID {
+		return ErrNotOwner
+	}
+
+	return s.End(session)
+}
+
+// End удаляет сессию и отзывает её последний access-токен
+func (s *SessionService) End(session *model.Session) error {
+	if err := s.sessions.DeleteSession(session.ID); err != nil {
+		return err
+	}
+	return s.revoker.Revoke(session.AccessTokenID, time.Now().Add(util.AccessTokenTTL))
+}
+
+func (s *SessionService) EndOthers(userID, keepSessionID int64) error {
+	sessions, err := s.sessions.GetActiveSessionsByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		if sessions[i].ID == keepSessionID {
+			continue
+		}
+		if err := s.End(&sessions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateOthers завершает остальные сессии пользователя и гасит все его access-токены.
+// Текущая сессия получает новый access-токен, который нужно вернуть клиенту.
+func (s *SessionService) InvalidateOthers(user *model.User, currentSessionID int64) (string, error) {
+	if err := s.EndOthers(user.ID, currentSessionID); err != nil {
+		return "", err
+	}
+
+	if err := s.revoker.RevokeUserTokens(user.Username); err != nil {
+		return "", err
+	}
+
+	accessTokenID, err := util.NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.sessions.SetSessionAccessTokenID(currentSessionID, accessTokenID); err != nil {
+		return "", err
+	}
+
+	return util.GenerateToken(user, currentSessionID, accessTokenID)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"microblog/internal/model"
+	"microblog/internal/repository"
+)
+
+// UserService отдаёт профили пользователей
+type UserService struct {
+	users repository.UserRepository
+	posts repository.PostRepository
+}
+
+func NewUserService(users repository.UserRepository, posts repository.PostRepository) *UserService {
+	return &UserService{users: users, posts: posts}
+}
+
+func (s *UserService) GetByUsername(username string) (*model.User, error) {
+	user, err := s.users.GetUserByUsername(username)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// Profile возвращает пользователя вместе с числом его постов
+func (s *UserService) Profile(username string) (*model.User, int64, error) {
+	user, err := s.GetByUsername(username)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	postsCount, _ := s.posts.GetPostsCountByAuthor(user.ID)
+	return user, postsCount, nil
+}
+
+// UpdateProfile обновляет только переданные поля профиля
+func (s *UserService) UpdateProfile(user *model.User, fields map[string]interface{}) (*model.User, error) {
+	return s.users.UpdateUserProfile(user.ID, fields)
+}
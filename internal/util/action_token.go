@@ -0,0 +1,64 @@
+package util
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+// Назначения одноразовых токенов, которые отправляются пользователю
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposeEmailChange       = "email_change"
+	PurposeMFAChallenge      = "mfa_challenge"
+	PurposeOIDCLogin         = "oidc_login"
+	PurposeDataExport        = "data_export"
+)
+
+var actionSecret []byte
+
+// ActionClaims — токен для одного конкретного действия; Subject содержит имя пользователя
+type ActionClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email,omitempty"`
+	Data    string `json:"data,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func GenerateActionToken(purpose, username, email, data string, ttl time.Duration) (string, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	claims := &ActionClaims{
+		Purpose: purpose,
+		Email:   email,
+		Data:    data,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   username,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(actionSecret)
+}
+
+func ValidateActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return actionSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*ActionClaims); ok && token.Valid && claims.Purpose == purpose {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid action token")
+}
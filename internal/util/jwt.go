@@ -2,47 +2,146 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"log"
 	"microblog/internal/config"
+	"microblog/internal/model"
 	"time"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute   // Короткий срок для access token
+	RefreshTokenTTL = 7 * 24 * time.Hour // 7 дней
+
+	// TokenTimePrecision — точность iat и exp в выдаваемых JWT. С iat сравниваются границы отзыва:
+	// при секундной точности токен, выданный за долю секунды до отзыва, оставался бы действительным.
+	TokenTimePrecision = time.Millisecond
+)
+
+func init() {
+	jwt.TimePrecision = TokenTimePrecision
+}
+
 var jwtSecret []byte
 var refreshSecret []byte
 
+// Метод подписи access-токенов; для асимметричных алгоритмов ключи лежат в signingKeys
+var accessMethod jwt.SigningMethod = jwt.SigningMethodHS256
+var signingKeys *keySet
+
 type Claims struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID int64  `json:"sid"`
+	// Заполнены только у токенов, выданных сторонним приложениям через OAuth
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// Язык из профиля, чтобы не читать пользователя из базы на каждый запрос
+	Locale string `json:"locale,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type RefreshClaims struct {
 	Username string `json:"username"`
+	FamilyID string `json:"fam"`
+	Sequence int    `json:"seq"`
 	jwt.RegisteredClaims
 }
 
-func InitJWT(cfg *config.Config) {
+func InitJWT(cfg *config.Config) error {
 	jwtSecret = []byte(cfg.JWT.Secret)
 	refreshSecret = []byte(cfg.JWT.RefreshSecret)
+
+	actionSecret = []byte(cfg.JWT.ActionSecret)
+	if len(actionSecret) == 0 {
+		log.Printf("Warning: JWT_ACTION_SECRET is not set, using the refresh token secret")
+		actionSecret = refreshSecret
+	}
+
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		accessMethod = jwt.SigningMethodHS256
+		signingKeys = nil
+		return nil
+	case "RS256":
+		accessMethod = jwt.SigningMethodRS256
+	case "EdDSA":
+		accessMethod = jwt.SigningMethodEdDSA
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", cfg.JWT.Algorithm)
+	}
+
+	if cfg.JWT.KeyRetention < AccessTokenTTL {
+		return fmt.Errorf("JWT key retention must be at least %s", AccessTokenTTL)
+	}
+	if cfg.JWT.KeyRotationInterval > 0 && cfg.JWT.KeyRotationInterval <= keyActivationDelay {
+		return fmt.Errorf("JWT key rotation interval must be longer than %s", keyActivationDelay)
+	}
+
+	keys, err := newKeySet(accessMethod, cfg.JWT.KeysDir, cfg.JWT.KeyRotationInterval, cfg.JWT.KeyRetention)
+	if err != nil {
+		return err
+	}
+	signingKeys = keys
+	return nil
 }
 
-func GenerateToken(username string) (string, error) {
+func GenerateToken(user *model.User, sessionID int64, tokenID string) (string, error) {
 	claims := &Claims{
-		Username: username,
+		Username:  user.Username,
+		Role:      user.Role,
+		SessionID: sessionID,
+		Locale:    user.Locale,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // Короткий срок для access token
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	return signAccessToken(claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// GenerateOAuthAccessToken выдаёт access-токен стороннему приложению с ограниченным набором областей.
+// Роль в токен не попадает: приложение не получает полномочий модератора или администратора.
+func GenerateOAuthAccessToken(user *model.User, clientID, scope, tokenID string) (string, error) {
+	claims := &Claims{
+		Username: user.Username,
+		Scope:    scope,
+		ClientID: clientID,
+		Locale:   user.Locale,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return signAccessToken(claims)
+}
+
+func signAccessToken(claims *Claims) (string, error) {
+	if signingKeys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(jwtSecret)
+	}
+
+	key, err := signingKeys.current()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(accessMethod, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
-func GenerateRefreshToken(username string) (string, error) {
+func GenerateRefreshToken(username, familyID string, sequence int) (string, error) {
 	claims := &RefreshClaims{
 		Username: username,
+		FamilyID: familyID,
+		Sequence: sequence,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 дней
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -52,9 +151,8 @@ func GenerateRefreshToken(username string) (string, error) {
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, accessKeyFunc,
+		jwt.WithValidMethods([]string{accessMethod.Alg()}), jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, err
@@ -67,10 +165,27 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+func accessKeyFunc(token *jwt.Token) (interface{}, error) {
+	if signingKeys == nil {
+		return jwtSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	return signingKeys.publicKey(kid)
+}
+
+// JWKS возвращает публичные ключи для проверки access-токенов; для HS256 список пуст
+func JWKS() []JWK {
+	if signingKeys == nil {
+		return []JWK{}
+	}
+	return signingKeys.jwks()
+}
+
 func ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return refreshSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
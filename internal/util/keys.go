@@ -0,0 +1,386 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rsaKeyBits = 2048
+	// Как часто можно перечитывать каталог ключей, встретив неизвестный kid или отдавая JWKS
+	keysReloadInterval = 10 * time.Second
+	// JWKSCacheTTL — сколько проверяющие сервисы могут кешировать JWKS
+	JWKSCacheTTL = 5 * time.Minute
+	// Новый ключ сначала только публикуется: подписывать им можно, когда он попал в JWKS
+	// всех экземпляров и устарели закешированные у проверяющих копии
+	keyActivationDelay = JWKSCacheTTL + keysReloadInterval
+	// Время выпуска ключа записано в начале kid: mtime файла меняется при восстановлении
+	// из резервной копии или монтировании из хранилища секретов
+	keyIDTimeLayout = "20060102T150405"
+)
+
+var errNoSigningKeys = errors.New("no signing keys found")
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type signingKey struct {
+	ID        string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// keySet — набор асимметричных ключей. Подписывает самый новый из ключей старше keyActivationDelay,
+// следующий ключ выпускается заранее, а старые остаются для проверки ещё retention после замены.
+type keySet struct {
+	mu               sync.RWMutex
+	method           jwt.SigningMethod
+	dir              string
+	rotationInterval time.Duration
+	retention        time.Duration
+	keys             []*signingKey
+	lastReload       time.Time
+}
+
+func newKeySet(method jwt.SigningMethod, dir string, rotationInterval, retention time.Duration) (*keySet, error) {
+	ks := &keySet{
+		method:           method,
+		dir:              dir,
+		rotationInterval: rotationInterval,
+		retention:        retention,
+	}
+
+	if dir == "" {
+		log.Printf("Warning: JWT_KEYS_DIR is not set, signing keys are kept in memory only")
+	} else {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		if err := ks.load(); err != nil && !errors.Is(err, errNoSigningKeys) {
+			return nil, err
+		}
+	}
+
+	if len(ks.keys) == 0 {
+		if _, err := ks.rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+// load читает *.pem из каталога; kid — имя файла без расширения. Если прочитать каталог
+// не удалось или ключей в нём нет, остаётся прежний набор.
+func (ks *keySet) load() error {
+	ks.lastReload = time.Now()
+
+	paths, err := filepath.Glob(filepath.Join(ks.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	var keys []*signingKey
+	for _, path := range paths {
+		key, err := readSigningKey(path)
+		if err != nil {
+			return fmt.Errorf("failed to read signing key %s: %w", path, err)
+		}
+		if !ks.matchesMethod(key.Private) {
+			log.Printf("Warning: skipping signing key %s: not suitable for %s", path, ks.method.Alg())
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return errNoSigningKeys
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	ks.keys = keys
+	ks.prune()
+	return nil
+}
+
+func (ks *keySet) matchesMethod(key crypto.Signer) bool {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return ks.method == jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		return ks.method == jwt.SigningMethodEdDSA
+	}
+	return false
+}
+
+// prune убирает ключи, которые были заменены более retention назад; замена происходит,
+// когда следующий ключ становится активным
+func (ks *keySet) prune() {
+	now := time.Now()
+	kept := ks.keys[:0]
+	for i, key := range ks.keys {
+		if i+1 < len(ks.keys) && now.Sub(ks.keys[i+1].CreatedAt) > keyActivationDelay+ks.retention {
+			if ks.dir != "" {
+				if err := os.Remove(filepath.Join(ks.dir, key.ID+".pem")); err != nil && !os.IsNotExist(err) {
+					log.Printf("Failed to remove retired signing key %s: %v", key.ID, err)
+				}
+			}
+			continue
+		}
+		kept = append(kept, key)
+	}
+	ks.keys = kept
+}
+
+// rotate создаёт новый ключ и делает его текущим; вызывается под блокировкой или при инициализации
+func (ks *keySet) rotate() (*signingKey, error) {
+	var private crypto.Signer
+	var err error
+	if ks.method == jwt.SigningMethodEdDSA {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := GenerateRandomString(4)
+	if err != nil {
+		return nil, err
+	}
+
+	// Время выпуска хранится в kid с точностью до секунды, как его потом прочитает load
+	now := time.Now().UTC().Truncate(time.Second)
+	key := &signingKey{
+		ID:        now.Format(keyIDTimeLayout) + "-" + suffix,
+		Private:   private,
+		CreatedAt: now,
+	}
+
+	if ks.dir != "" {
+		if err := writeSigningKey(filepath.Join(ks.dir, key.ID+".pem"), private); err != nil {
+			return nil, err
+		}
+	}
+
+	ks.keys = append(ks.keys, key)
+	ks.prune()
+	log.Printf("JWT signing key rotated, new kid %s", key.ID)
+	return key, nil
+}
+
+func (ks *keySet) current() (*signingKey, error) {
+	ks.mu.RLock()
+	due := ks.rotationDue()
+	ks.mu.RUnlock()
+
+	if due {
+		ks.mu.Lock()
+		// Следующий ключ мог выпустить другой экземпляр или другой запрос, пока ждали блокировку
+		if ks.dir != "" {
+			if err := ks.load(); err != nil {
+				log.Printf("Failed to reload signing keys: %v", err)
+			}
+		}
+		if ks.rotationDue() {
+			if _, err := ks.rotate(); err != nil {
+				ks.mu.Unlock()
+				return nil, err
+			}
+		}
+		ks.mu.Unlock()
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(ks.keys) == 0 {
+		return nil, errNoSigningKeys
+	}
+	return ks.active(), nil
+}
+
+// rotationDue сообщает, пора ли выпускать следующий ключ, чтобы он стал активным к концу rotationInterval
+func (ks *keySet) rotationDue() bool {
+	if len(ks.keys) == 0 {
+		return true
+	}
+	newest := ks.keys[len(ks.keys)-1]
+	return ks.rotationInterval > 0 && time.Since(newest.CreatedAt) >= ks.rotationInterval-keyActivationDelay
+}
+
+// active возвращает самый новый опубликованный достаточно давно ключ. Если таких нет,
+// подписывает самый старый: при первом запуске закешированных JWKS ещё нет.
+// Вызывается под блокировкой с непустым набором.
+func (ks *keySet) active() *signingKey {
+	for i := len(ks.keys) - 1; i >= 0; i-- {
+		if time.Since(ks.keys[i].CreatedAt) >= keyActivationDelay {
+			return ks.keys[i]
+		}
+	}
+	return ks.keys[0]
+}
+
+func (ks *keySet) find(kid string) *signingKey {
+	for _, key := range ks.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+func (ks *keySet) publicKey(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key := ks.find(kid)
+	canReload := ks.dir != "" && time.Since(ks.lastReload) > keysReloadInterval
+	ks.mu.RUnlock()
+
+	// Ключ мог выпустить другой экземпляр сервиса с тем же каталогом
+	if key == nil && canReload {
+		ks.mu.Lock()
+		if err := ks.load(); err != nil {
+			log.Printf("Failed to reload signing keys: %v", err)
+		}
+		key = ks.find(kid)
+		ks.mu.Unlock()
+	}
+
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	return key.Private.Public(), nil
+}
+
+// jwks публикует все ключи, включая ещё не активные; каталог перечитывается, чтобы в ответ
+// попали ключи, выпущенные другими экземплярами
+func (ks *keySet) jwks() []JWK {
+	ks.mu.RLock()
+	canReload := ks.dir != "" && time.Since(ks.lastReload) > keysReloadInterval
+	ks.mu.RUnlock()
+
+	if canReload {
+		ks.mu.Lock()
+		if err := ks.load(); err != nil {
+			log.Printf("Failed to reload signing keys: %v", err)
+		}
+		ks.mu.Unlock()
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := make([]JWK, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: ks.method.Alg(),
+		}
+		switch public := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+func readSigningKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &signingKey{
+		ID:        id,
+		Private:   private,
+		CreatedAt: keyCreatedAt(id),
+	}, nil
+}
+
+// keyCreatedAt достаёт время выпуска из kid. Ключ, положенный в каталог вручную под другим
+// именем, считается давно выпущенным: он сразу активен, а замена ему выпускается при первой подписи.
+func keyCreatedAt(id string) time.Time {
+	prefix, _, _ := strings.Cut(id, "-")
+	createdAt, err := time.Parse(keyIDTimeLayout, prefix)
+	if err != nil {
+		return time.Time{}
+	}
+	return createdAt
+}
+
+func writeSigningKey(path string, private crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	// Другие экземпляры не должны прочитать наполовину записанный файл
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// GenerateCodeVerifier возвращает PKCE code_verifier (RFC 7636): 64 символа из [0-9a-f]
+func GenerateCodeVerifier() (string, error) {
+	return GenerateRandomString(32)
+}
+
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func VerifyCodeChallengeS256(verifier, challenge string) bool {
+	return subtle.ConstantTimeCompare([]byte(CodeChallengeS256(verifier)), []byte(challenge)) == 1
+}
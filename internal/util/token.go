@@ -0,0 +1,38 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func NewTokenID() (string, error) {
+	return GenerateRandomString(16)
+}
+
+func GenerateRandomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken возвращает SHA-256 от случайного токена; так токены хранятся в базе
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Персональные токены доступа легко отличить от JWT по префиксу
+const PersonalAccessTokenPrefix = "mbp_"
+
+// GeneratePersonalAccessToken возвращает сам токен и короткий префикс для отображения в списке
+func GeneratePersonalAccessToken() (string, string, error) {
+	random, err := GenerateRandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	token := PersonalAccessTokenPrefix + random
+	return token, token[:len(PersonalAccessTokenPrefix)+8], nil
+}
@@ -0,0 +1,17 @@
+package util
+
+import "regexp"
+
+var (
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,}$`)
+	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+)
+
+// IsValidUsername — латиница, цифры, _ и -, не короче трёх символов
+func IsValidUsername(username string) bool {
+	return usernameRegex.MatchString(username)
+}
+
+func IsValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
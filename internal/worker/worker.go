@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Task — периодическая фоновая задача; Run вызывается сразу при запуске и затем каждые Interval
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Сколько разовых заданий может выполняться одновременно; остальные отклоняются
+const maxJobs = 32
+
+// Pool выполняет периодические задачи и разовые задания в фоне и дожидается их при остановке
+type Pool struct {
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped bool
+	wg      sync.WaitGroup
+	// Сколько задач запущено и сколько из них ещё работает
+	started int
+	alive   atomic.Int32
+	// Свободные места для разовых заданий
+	jobSlots chan struct{}
+}
+
+func New() *Pool {
+	return &Pool{jobSlots: make(chan struct{}, maxJobs)}
+}
+
+func (p *Pool) Start(tasks ...Task) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil || p.stopped {
+		return
+	}
+
+	var ctx context.Context
+	ctx, p.cancel = context.WithCancel(context.Background())
+	p.started = len(tasks)
+	for _, task := range tasks {
+		p.wg.Add(1)
+		p.alive.Add(1)
+		go p.run(ctx, task)
+	}
+}
+
+// Go выполняет разовое задание в фоне; Stop дождётся его так же, как периодических задач.
+// Возвращает false, если одновременно выполняется уже maxJobs заданий или пул остановлен.
+func (p *Pool) Go(job func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return false
+	}
+	select {
+	case p.jobSlots <- struct{}{}:
+	default:
+		return false
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.jobSlots }()
+		job()
+	}()
+	return true
+}
+
+// Running сообщает, что пул не остановлен и ни одна из запущенных задач не завершилась.
+// До Start задач нет, и пул считается работающим: сервер запускает их до того, как принять трафик.
+func (p *Pool) Running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !p.stopped && int(p.alive.Load()) == p.started
+}
+
+// Stop останавливает задачи и ждёт завершения текущих запусков и разовых заданий, но не дольше, чем живёт ctx
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopped = true
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) run(ctx context.Context, task Task) {
+	defer p.wg.Done()
+	defer p.alive.Add(-1)
+
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := task.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Background task %s failed: %v", task.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}